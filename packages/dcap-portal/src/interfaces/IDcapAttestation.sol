@@ -11,6 +11,12 @@ interface IDcapAttestation {
     // Function to get the fee base point
     function getBp() external view returns (uint16);
 
+    // Function to get the program identifiers accepted by the verifier of the zk coprocessor
+    function programIdentifiers(uint8 zkCoProcessorType) external view returns (bytes32[] memory);
+
+    // Function to get the latest program identifier, for the deployments accepting only one
+    function programIdentifier(uint8 zkCoProcessorType) external view returns (bytes32);
+
     // Function to verify and attest on-chain using a raw quote
     function verifyAndAttestOnChain(bytes calldata rawQuote)
         external
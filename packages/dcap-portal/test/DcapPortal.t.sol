@@ -4,6 +4,7 @@ pragma solidity ^0.8.13;
 import {Test, console} from "forge-std/Test.sol";
 import {DcapPortal} from "@dcap-portal/src/DcapPortal.sol";
 import {IDcapPortal} from "@dcap-portal/src/interfaces/IDcapPortal.sol";
+import {IDcapAttestation} from "@dcap-portal/src/interfaces/IDcapAttestation.sol";
 import {DcapLibCallback} from "@dcap-portal/src/lib/DcapLibCallback.sol";
 import {VerifiedCounter} from "@dcap-portal/src/examples/VerifiedCounter.sol";
 import {TransparentUpgradeableProxy} from "@openzeppelin/contracts/proxy/transparent/TransparentUpgradeableProxy.sol";
@@ -67,6 +68,25 @@ contract DcapPortalTest is Test {
         portal.verifyAndAttestOnChain{value: GAS_USED}(rawQuote, callback);
     }
 
+    function test_MockZkProof() public {
+        bytes memory verifiedOutput = hex"0102030405";
+        bytes memory output = abi.encodePacked(uint16(verifiedOutput.length), verifiedOutput, uint64(block.timestamp));
+        bytes memory proof = abi.encodePacked(bytes4(0x4d4f434b), keccak256(output));
+        IDcapPortal.Callback memory callback =
+            IDcapPortal.Callback(0, address(counter), abi.encodeWithSignature("debugOutput()"));
+
+        vm.expectEmit(true, true, true, true);
+        emit VerifiedCounter.AttestationOutput(verifiedOutput);
+        portal.verifyAndAttestWithZKProof{value: GAS_USED}(
+            output, IDcapAttestation.ZkCoProcessorType.Unknown, proof, callback
+        );
+
+        vm.expectRevert(abi.encodeWithSignature("Invalid_Mock_Proof()"));
+        portal.verifyAndAttestWithZKProof{value: GAS_USED}(
+            output, IDcapAttestation.ZkCoProcessorType.RiscZero, proof, callback
+        );
+    }
+
     function testFuzz_SetNumber(uint256 x) public {
         bytes memory callData = abi.encodeWithSignature("setNumber(uint256)", x);
         bytes memory rawQuote = hex"0102030405";
@@ -15,6 +15,11 @@ contract MockDcapAttestation {
     error Insuccifient_Funds();
     // c40a532b
     error Withdrawal_Failed();
+    // 1bce0e73
+    error Invalid_Mock_Proof();
+
+    // "MOCK", the selector of the proofs generated by zkdcap.MockProver
+    bytes4 constant MOCK_PROOF_SELECTOR = 0x4d4f434b;
 
     function verifyAndAttestOnChain(bytes calldata rawQuote)
         external
@@ -28,6 +33,28 @@ contract MockDcapAttestation {
         return (true, rawQuote);
     }
 
+    /// @dev only accepts the deterministic proofs of zkdcap.MockProver,
+    /// zkCoprocessor must be Unknown(0) and proofBytes is abi.encodePacked(MOCK_PROOF_SELECTOR, keccak256(output))
+    function verifyAndAttestWithZKProof(bytes calldata output, uint8 zkCoprocessor, bytes calldata proofBytes)
+        external
+        payable
+        collectFee
+        returns (bool success, bytes memory verifiedOutput)
+    {
+        if (
+            zkCoprocessor != 0
+                || keccak256(proofBytes) != keccak256(abi.encodePacked(MOCK_PROOF_SELECTOR, keccak256(output)))
+        ) {
+            revert Invalid_Mock_Proof();
+        }
+        for (uint256 i = 0; i < 300; i++) {
+            storages[i] = i;
+        }
+        // the output is prefixed with the length of the verified output
+        uint256 len = uint16(bytes2(output[0:2]));
+        return (true, output[2:2 + len]);
+    }
+
     function setBp(uint16 _newBp) public virtual {
         if (_newBp > MAX_BP) {
             revert BP_Not_Valid();
@@ -11,6 +11,7 @@ import (
 
 var (
 	ErrUnexpectEnum = logex.Define("unexpected enum[%T]: %v")
+	ErrUnexpectEOF  = logex.Define("unexpected end of data: want %v bytes, got %v")
 )
 
 var led = binary.LittleEndian
@@ -187,7 +188,13 @@ func (b Bytes) Bincode() []byte {
 }
 
 func (b *Bytes) FromBin(data []byte) ([]byte, error) {
+	if len(data) < 8 {
+		return nil, ErrUnexpectEOF.Format(8, len(data))
+	}
 	length, data := ReadUint64(data)
+	if length > uint64(len(data)) {
+		return nil, ErrUnexpectEOF.Format(length, len(data))
+	}
 	*b = make([]byte, int(length))
 	copy(*b, data[:len(*b)])
 	return data[len(*b):], nil
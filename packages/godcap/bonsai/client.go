@@ -1,15 +1,14 @@
 package bonsai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/automata-network/dcap-sdk/packages/godcap/observe"
 	"github.com/chzyer/logex"
 )
 
@@ -25,6 +24,18 @@ type Config struct {
 	ApiKey           string `json:"api_key"`
 	Version          string `json:"version"`
 	PollIntervalSecs int    `json:"poll_interval_secs"`
+	// TimeoutSecs limits each http request, defaults to 60
+	TimeoutSecs int `json:"timeout_secs"`
+	// MaxRetries of a request failed with 429, 5xx or a network error, defaults to 3, -1 disables the retries.
+	// The POST requests are only retried on 429, 503 or a refused connection, see isRetryable.
+	MaxRetries int `json:"max_retries"`
+	// RetryBackoffMs is the initial backoff of the retries, doubled on each retry, defaults to 500
+	RetryBackoffMs int `json:"retry_backoff_ms"`
+
+	// HTTPClient sends the requests, defaults to http.DefaultClient
+	HTTPClient *http.Client `json:"-"`
+	// Observer receives the progress of the sessions, defaults to observe.LogObserver
+	Observer observe.Observer `json:"-"`
 }
 
 func (c *Config) Init() error {
@@ -43,41 +54,45 @@ func (c *Config) Init() error {
 	if c.PollIntervalSecs == 0 {
 		c.PollIntervalSecs = 5
 	}
+	if c.TimeoutSecs == 0 {
+		c.TimeoutSecs = 60
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = 3
+	}
+	if c.RetryBackoffMs == 0 {
+		c.RetryBackoffMs = 500
+	}
 	return nil
 }
 
 type Client struct {
-	cfg *Config
+	cfg    *Config
+	events *observe.Emitter
 }
 
 func NewClient(cfg *Config) (*Client, error) {
 	if err := cfg.Init(); err != nil {
 		return nil, logex.Trace(err)
 	}
-	return &Client{cfg: cfg}, nil
+	return &Client{cfg: cfg, events: observe.NewEmitter(observe.SourceBonsai, cfg.Observer)}, nil
 }
 
 type ProveInfo struct {
 	/// receipt from the computation
 	Receipt *Receipt
+	/// bincode encoded receipt
+	ReceiptBytes []byte
 	/// stats about cycle counts of the execution
 	Stats *SessionStats
 }
 
 func (c *Client) Prove(ctx context.Context, imageID string, input []byte, kind ReceiptKind) (*ProveInfo, error) {
-	inputId, err := c.UploadInput(input)
+	sess, err := c.Submit(ctx, imageID, input)
 	if err != nil {
-		return nil, logex.Trace(err, "uploadInput")
-	}
-	sess, err := c.CreateSessionWithLimit(&ProofReq{
-		Img:         imageID,
-		Input:       inputId,
-		Assumptions: []string{},
-	})
-	if err != nil {
-		return nil, logex.Trace(err, "createSess")
+		return nil, logex.Trace(err)
 	}
-	polling := time.Duration(c.cfg.PollIntervalSecs) * time.Second
+	polling := c.PollInterval()
 	proveInfo, err := sess.Poll(ctx, polling)
 	if err != nil {
 		return nil, logex.Trace(err)
@@ -90,7 +105,6 @@ func (c *Client) Prove(ctx context.Context, imageID string, input []byte, kind R
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
-	logex.Infof("create snark session: %v", snarkSess.uuid)
 
 	receipt, err := snarkSess.Poll(ctx, polling)
 	if err != nil {
@@ -100,58 +114,51 @@ func (c *Client) Prove(ctx context.Context, imageID string, input []byte, kind R
 	return proveInfo, nil
 }
 
-func (c *Client) s3(method string, url string, body io.Reader) ([]byte, error) {
-	req, err := http.NewRequest(method, url, body)
-	if err != nil {
-		return nil, logex.Trace(err)
-	}
-	httpResponse, err := http.DefaultClient.Do(req)
+// Submit uploads the input and creates a session proving the image, it doesn't wait for the proof
+func (c *Client) Submit(ctx context.Context, imageID string, input []byte) (*Session, error) {
+	inputId, err := c.UploadInput(ctx, input)
 	if err != nil {
-		return nil, logex.Trace(err)
+		return nil, logex.Trace(err, "uploadInput")
 	}
-	defer httpResponse.Body.Close()
-	httpBody, err := io.ReadAll(httpResponse.Body)
+	sess, err := c.CreateSessionWithLimit(ctx, &ProofReq{
+		Img:         imageID,
+		Input:       inputId,
+		Assumptions: []string{},
+	})
 	if err != nil {
-		return nil, logex.Trace(err)
-	}
-	if httpResponse.StatusCode/100 != 2 {
-		return nil, logex.NewErrorf("http remote error: %v", string(httpBody))
+		return nil, logex.Trace(err, "createSess")
 	}
-	return httpBody, nil
+	return sess, nil
 }
 
-func (c *Client) api(method string, path string, body io.Reader, response interface{}) (int, error) {
-	// defaults to BAD_REQUEST error if failed before http call
-	statusCode := http.StatusBadRequest
+// PollInterval returns the configured interval of polling the sessions
+func (c *Client) PollInterval() time.Duration {
+	return time.Duration(c.cfg.PollIntervalSecs) * time.Second
+}
 
-	req, err := http.NewRequest(method, fmt.Sprintf("%v/%v", c.cfg.Url, path), body)
-	if err != nil {
-		return statusCode, logex.Trace(err)
-	}
-	if c.cfg.ApiKey == "" {
-		return statusCode, logex.NewError("BONSAI_API_KEY is required")
-	}
-	if method == http.MethodPost {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.Header.Set("x-api-key", c.cfg.ApiKey)
-	req.Header.Set("x-risc0-version", c.cfg.Version)
-	httpResponse, err := http.DefaultClient.Do(req)
+// Session returns the handle of an existing session, e.g. to resume polling after a restart
+func (c *Client) Session(uuid string) *Session {
+	return &Session{uuid: uuid, client: c}
+}
 
-	statusCode = httpResponse.StatusCode
+// SnarkSession returns the handle of an existing snark session
+func (c *Client) SnarkSession(uuid string) *SnarkSession {
+	return &SnarkSession{uuid: uuid, client: c}
+}
 
+func (c *Client) s3(ctx context.Context, method string, url string, body []byte) ([]byte, error) {
+	_, httpBody, err := c.do(ctx, method, url, body, nil)
 	if err != nil {
-		return statusCode, logex.Trace(err)
+		return nil, logex.Trace(err)
 	}
-	defer httpResponse.Body.Close()
-	httpBody, err := io.ReadAll(httpResponse.Body)
+	return httpBody, nil
+}
+
+func (c *Client) api(ctx context.Context, method string, path string, body []byte, response interface{}) (int, error) {
+	statusCode, httpBody, err := c.apiRaw(ctx, method, path, body)
 	if err != nil {
 		return statusCode, logex.Trace(err)
 	}
-
-	if httpResponse.StatusCode/100 != 2 {
-		return statusCode, logex.NewErrorf("http remote error: %v", string(httpBody))
-	}
 	if response != nil && len(httpBody) != 0 {
 		if err := json.Unmarshal(httpBody, response); err != nil {
 			return statusCode, logex.Trace(err)
@@ -160,32 +167,52 @@ func (c *Client) api(method string, path string, body io.Reader, response interf
 	return statusCode, nil
 }
 
+// apiRaw returns the response body of the api without decoding it
+func (c *Client) apiRaw(ctx context.Context, method string, path string, body []byte) (int, []byte, error) {
+	if c.cfg.ApiKey == "" {
+		// defaults to BAD_REQUEST error if failed before http call
+		return http.StatusBadRequest, nil, logex.NewError("BONSAI_API_KEY is required")
+	}
+	header := make(http.Header)
+	if method == http.MethodPost {
+		header.Set("Content-Type", "application/json")
+	}
+	header.Set("x-api-key", c.cfg.ApiKey)
+	header.Set("x-risc0-version", c.cfg.Version)
+	statusCode, httpBody, err := c.do(ctx, method, fmt.Sprintf("%v/%v", c.cfg.Url, path), body, header)
+	if err != nil {
+		return statusCode, nil, logex.Trace(err)
+	}
+	return statusCode, httpBody, nil
+}
+
 type UploadResponse struct {
 	Url  string `json:"url"`
 	Uuid string `json:"uuid"`
 }
 
-func (c *Client) UploadInput(input []byte) (string, error) {
+func (c *Client) UploadInput(ctx context.Context, input []byte) (string, error) {
 	var response UploadResponse
-	if _, err := c.api(http.MethodGet, "inputs/upload", nil, &response); err != nil {
+	if _, err := c.api(ctx, http.MethodGet, "inputs/upload", nil, &response); err != nil {
 		return "", logex.Trace(err)
 	}
-	if _, err := c.s3(http.MethodPut, response.Url, bytes.NewReader(input)); err != nil {
+	if _, err := c.s3(ctx, http.MethodPut, response.Url, input); err != nil {
 		return "", logex.Trace(err)
 	}
+	c.events.Emit(&observe.Event{Kind: observe.EventInputUploaded, ID: response.Uuid})
 	return response.Uuid, nil
 }
 
-func (c *Client) UploadImage(imageID string, elf []byte) error {
+func (c *Client) UploadImage(ctx context.Context, imageID string, elf []byte) error {
 	var response UploadResponse
-	statusCode, err := c.api(http.MethodGet, "images/upload/"+imageID, nil, &response)
+	statusCode, err := c.api(ctx, http.MethodGet, "images/upload/"+imageID, nil, &response)
 	if err != nil {
 		return logex.Trace(err)
 	}
 
 	if statusCode == http.StatusOK {
 		// upload the image
-		if _, err := c.s3(http.MethodPut, response.Url, bytes.NewReader(elf)); err != nil {
+		if _, err := c.s3(ctx, http.MethodPut, response.Url, elf); err != nil {
 			return logex.Trace(err)
 		}
 	}
@@ -216,9 +243,13 @@ type SnarkSession struct {
 	client *Client
 }
 
+func (s *SnarkSession) UUID() string {
+	return s.uuid
+}
+
 func (s *SnarkSession) Status(ctx context.Context) (*SnarkStatusRes, error) {
 	var res SnarkStatusRes
-	if _, err := s.client.api(http.MethodGet, fmt.Sprintf("snark/status/%v", s.uuid), nil, &res); err != nil {
+	if _, err := s.client.api(ctx, http.MethodGet, fmt.Sprintf("snark/status/%v", s.uuid), nil, &res); err != nil {
 		return nil, logex.Trace(err, "SessionStatus")
 	}
 	return &res, nil
@@ -228,6 +259,7 @@ func (s *SnarkSession) Poll(ctx context.Context, interval time.Duration) (*Recei
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	errRetryTime := 3
+	lastState := ""
 	for {
 		select {
 		case <-ctx.Done():
@@ -242,15 +274,18 @@ func (s *SnarkSession) Poll(ctx context.Context, interval time.Duration) (*Recei
 				errRetryTime--
 				continue
 			}
+			if status.Status != lastState {
+				lastState = status.Status
+				s.client.events.Emit(&observe.Event{Kind: observe.EventStateChanged, ID: s.uuid, State: status.Status})
+			}
 			switch status.Status {
 			case "RUNNING":
-				logex.Infof("SnarkSession %v is running", s.uuid)
 				continue
 			case "SUCCEEDED":
 				if status.Output == "" {
 					return nil, logex.NewErrorf("missing receipt: %v", status)
 				}
-				data, err := s.client.s3(http.MethodGet, status.Output, nil)
+				data, err := s.client.s3(ctx, http.MethodGet, status.Output, nil)
 				if err != nil {
 					return nil, logex.Trace(err)
 				}
@@ -259,9 +294,16 @@ func (s *SnarkSession) Poll(ctx context.Context, interval time.Duration) (*Recei
 				if err != nil {
 					return nil, logex.Trace(err)
 				}
+				s.client.events.Emit(&observe.Event{Kind: observe.EventFulfilled, ID: s.uuid})
 				return receipt, nil
 			default:
-				return nil, logex.NewErrorf("unexpected status: %v", status)
+				errMsg := ""
+				if status.ErrorMsg != nil {
+					errMsg = *status.ErrorMsg
+				}
+				err := ErrSessionFailed.Format(s.uuid, status.Status, errMsg)
+				s.client.events.Emit(&observe.Event{Kind: observe.EventFailed, ID: s.uuid, State: status.Status, Err: err})
+				return nil, err
 			}
 		}
 	}
@@ -272,12 +314,13 @@ type Session struct {
 	client *Client
 }
 
-func (c *Client) CreateSessionWithLimit(proof *ProofReq) (*Session, error) {
+func (c *Client) CreateSessionWithLimit(ctx context.Context, proof *ProofReq) (*Session, error) {
 	req, _ := json.Marshal(proof)
 	var response CreateSessRes
-	if _, err := c.api(http.MethodPost, "sessions/create", bytes.NewReader(req), &response); err != nil {
+	if _, err := c.api(ctx, http.MethodPost, "sessions/create", req, &response); err != nil {
 		return nil, logex.Trace(err)
 	}
+	c.events.Emit(&observe.Event{Kind: observe.EventSessionCreated, ID: response.Uuid})
 	return &Session{uuid: response.Uuid, client: c}, nil
 }
 
@@ -331,9 +374,13 @@ type SessionStats struct {
 	Cycles uint64 `json:"cycles"`
 }
 
+func (s *Session) UUID() string {
+	return s.uuid
+}
+
 func (s *Session) Status(ctx context.Context) (*SessionStatusRes, error) {
 	var res SessionStatusRes
-	if _, err := s.client.api(http.MethodGet, fmt.Sprintf("sessions/status/%v", s.uuid), nil, &res); err != nil {
+	if _, err := s.client.api(ctx, http.MethodGet, fmt.Sprintf("sessions/status/%v", s.uuid), nil, &res); err != nil {
 		return nil, logex.Trace(err, "SessionStatus")
 	}
 	return &res, nil
@@ -363,9 +410,10 @@ type SnarkStatusRes struct {
 func (s *Session) CreateSnark(ctx context.Context) (*SnarkSession, error) {
 	data, _ := json.Marshal(&SnarkReq{SessionID: s.uuid})
 	var response CreateSessRes
-	if _, err := s.client.api(http.MethodPost, "snark/create", bytes.NewReader(data), &response); err != nil {
+	if _, err := s.client.api(ctx, http.MethodPost, "snark/create", data, &response); err != nil {
 		return nil, logex.Trace(err)
 	}
+	s.client.events.Emit(&observe.Event{Kind: observe.EventSnarkStarted, ID: response.Uuid})
 	return &SnarkSession{uuid: response.Uuid, client: s.client}, nil
 }
 
@@ -373,6 +421,7 @@ func (s *Session) Poll(ctx context.Context, interval time.Duration) (*ProveInfo,
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	errRetryTime := 3
+	lastState := ""
 	for {
 		select {
 		case <-ctx.Done():
@@ -387,16 +436,31 @@ func (s *Session) Poll(ctx context.Context, interval time.Duration) (*ProveInfo,
 				errRetryTime--
 				continue
 			}
+			state := status.Status
+			if status.State != "" {
+				state = fmt.Sprintf("%v: %v", status.Status, status.State)
+			}
+			if state != lastState {
+				lastState = state
+				s.client.events.Emit(&observe.Event{Kind: observe.EventStateChanged, ID: s.uuid, State: state})
+			}
 			switch status.Status {
 			case "RUNNING":
-				logex.Infof("Session %v is running", s.uuid)
 				continue
 			case "SUCCEEDED":
 				if status.ReceiptURL == "" {
 					return nil, logex.NewErrorf("missing receipt: %v", status)
 				}
-				logex.Infof("stat: %#v", status.Stats)
-				data, err := s.client.s3(http.MethodGet, status.ReceiptURL, nil)
+				if status.Stats != nil {
+					s.client.events.Emit(&observe.Event{
+						Kind:        observe.EventCycles,
+						ID:          s.uuid,
+						Cycles:      status.Stats.Cycles,
+						TotalCycles: status.Stats.TotalCycles,
+						Segments:    status.Stats.Segments,
+					})
+				}
+				data, err := s.client.s3(ctx, http.MethodGet, status.ReceiptURL, nil)
 				if err != nil {
 					return nil, logex.Trace(err)
 				}
@@ -405,12 +469,16 @@ func (s *Session) Poll(ctx context.Context, interval time.Duration) (*ProveInfo,
 				if err != nil {
 					return nil, logex.Trace(err)
 				}
+				s.client.events.Emit(&observe.Event{Kind: observe.EventFulfilled, ID: s.uuid})
 				return &ProveInfo{
-					Receipt: receipt,
-					Stats:   status.Stats,
+					Receipt:      receipt,
+					ReceiptBytes: data,
+					Stats:        status.Stats,
 				}, nil
 			default:
-				return nil, logex.NewErrorf("unexpected status: %v", status)
+				err := ErrSessionFailed.Format(s.uuid, status.Status, status.ErrorMsg)
+				s.client.events.Emit(&observe.Event{Kind: observe.EventFailed, ID: s.uuid, State: status.Status, Err: err})
+				return nil, err
 			}
 		}
 	}
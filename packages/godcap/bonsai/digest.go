@@ -0,0 +1,251 @@
+package bonsai
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bincode"
+
+	"github.com/chzyer/logex"
+)
+
+var (
+	ErrImageIdMismatch    = logex.Define("image id mismatch: want %v, got %v")
+	ErrJournalMismatch    = logex.Define("journal mismatch: want %v, got %v")
+	ErrClaimMismatch      = logex.Define("claim digest mismatch: want %v, got %v")
+	ErrUnexpectedExitCode = logex.Define("unexpected exit code: %v")
+)
+
+// Bytes returns the digest in the byte order of risc0, i.e. the little endian words
+func (d *Digest) Bytes() []byte {
+	out := make([]byte, 0, 32)
+	for i := 0; i < 8; i++ {
+		out = led.AppendUint32(out, d[i])
+	}
+	return out
+}
+
+// Hex returns the hex encoded Bytes, in the same format as the image id
+func (d *Digest) Hex() string {
+	return hex.EncodeToString(d.Bytes())
+}
+
+// DigestFromBytes converts the 32 bytes in the byte order of risc0
+func DigestFromBytes(data []byte) (Digest, error) {
+	var d Digest
+	if len(data) != 32 {
+		return d, logex.NewErrorf("invalid digest size: %v", len(data))
+	}
+	d.FromBin(data)
+	return d, nil
+}
+
+// DigestFromHex decodes the digest from the hex string, e.g. the image id
+func DigestFromHex(s string) (Digest, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return Digest{}, logex.Trace(err)
+	}
+	return DigestFromBytes(data)
+}
+
+func sha256Digest(data []byte) Digest {
+	hash := sha256.Sum256(data)
+	d, _ := DigestFromBytes(hash[:])
+	return d
+}
+
+// taggedStruct hashes the tag, the digests, the words and the number of the digests
+func taggedStruct(tag string, down []Digest, data []uint32) Digest {
+	tagDigest := sha256.Sum256([]byte(tag))
+	buf := make([]byte, 0, 32+32*len(down)+4*len(data)+2)
+	buf = append(buf, tagDigest[:]...)
+	for i := range down {
+		buf = append(buf, down[i].Bytes()...)
+	}
+	for _, word := range data {
+		buf = led.AppendUint32(buf, word)
+	}
+	buf = led.AppendUint16(buf, uint16(len(down)))
+	return sha256Digest(buf)
+}
+
+// taggedList folds the list from the right, the digest of an empty list is zero
+func taggedList(tag string, list []Digest) Digest {
+	var tail Digest
+	for i := len(list) - 1; i >= 0; i-- {
+		tail = taggedStruct(tag, []Digest{list[i], tail}, nil)
+	}
+	return tail
+}
+
+func maybePrunedDigest[T bincode.FromBin](m *MaybePruned[T], digest func(T) (Digest, error)) (Digest, error) {
+	switch {
+	case m.Pruned != nil:
+		return *m.Pruned, nil
+	case m.Value != nil:
+		return digest(*m.Value)
+	default:
+		return Digest{}, logex.NewErrorf("empty MaybePruned")
+	}
+}
+
+// Digest is the tagged struct of the merkle root and the pc
+func (s *SystemState) Digest() (Digest, error) {
+	return taggedStruct("risc0.SystemState", []Digest{s.MerkleRoot}, []uint32{s.Pc}), nil
+}
+
+// Digest is the tagged struct of the claim and the control root
+func (a *Assumption) Digest() (Digest, error) {
+	return taggedStruct("risc0.Assumption", []Digest{a.Claim, a.ControlRoot}, nil), nil
+}
+
+// Digest is the sha256 of the journal bytes
+func (j *Journal) Digest() Digest {
+	return sha256Digest(j.Bytes)
+}
+
+// Digest is the tagged struct of the journal digest and the assumptions list
+func (o *Output) Digest() (Digest, error) {
+	journal, err := maybePrunedDigest(&o.Journal, func(journal *bincode.Bytes) (Digest, error) {
+		return sha256Digest(*journal), nil
+	})
+	if err != nil {
+		return Digest{}, logex.Trace(err, "journal")
+	}
+	assumptions, err := maybePrunedDigest(&o.Assumptions, func(list *bincode.Collection[*MaybePruned[*Assumption]]) (Digest, error) {
+		digests := make([]Digest, len(*list))
+		for i, item := range *list {
+			d, err := maybePrunedDigest(item, (*Assumption).Digest)
+			if err != nil {
+				return Digest{}, logex.Trace(err)
+			}
+			digests[i] = d
+		}
+		return taggedList("risc0.Assumptions", digests), nil
+	})
+	if err != nil {
+		return Digest{}, logex.Trace(err, "assumptions")
+	}
+	return taggedStruct("risc0.Output", []Digest{journal, assumptions}, nil), nil
+}
+
+// Pair returns the system and user exit code.
+// risc0 doesn't hash the exit code, the pair is committed as the words of the claim digest.
+func (e *ExitCode) Pair() ([2]uint32, error) {
+	switch {
+	case e.Halted != nil:
+		return [2]uint32{0, *e.Halted}, nil
+	case e.Paused != nil:
+		return [2]uint32{1, *e.Paused}, nil
+	case e.SystemSplit != nil:
+		return [2]uint32{2, 0}, nil
+	case e.SessionLimit != nil:
+		return [2]uint32{2, 2}, nil
+	default:
+		return [2]uint32{}, logex.NewErrorf("unknown exit code: %v", e.Type)
+	}
+}
+
+// Digest is the tagged struct of the input, pre, post, output and the exit code, i.e. the claim digest of the seal
+func (c *ReceiptClaim) Digest() (Digest, error) {
+	input, err := maybePrunedDigest(&c.Input, func(input *bincode.Option[*Input]) (Digest, error) {
+		if input.Val != nil {
+			return Digest{}, logex.NewErrorf("unsupported claim input")
+		}
+		return Digest{}, nil
+	})
+	if err != nil {
+		return Digest{}, logex.Trace(err, "input")
+	}
+	pre, err := maybePrunedDigest(&c.Pre, (*SystemState).Digest)
+	if err != nil {
+		return Digest{}, logex.Trace(err, "pre")
+	}
+	post, err := maybePrunedDigest(&c.Post, (*SystemState).Digest)
+	if err != nil {
+		return Digest{}, logex.Trace(err, "post")
+	}
+	output, err := maybePrunedDigest(&c.Output, func(output *bincode.Option[*Output]) (Digest, error) {
+		if output.Val == nil {
+			return Digest{}, nil
+		}
+		return (*output.Val).Digest()
+	})
+	if err != nil {
+		return Digest{}, logex.Trace(err, "output")
+	}
+	exitCode, err := c.ExitCode.Pair()
+	if err != nil {
+		return Digest{}, logex.Trace(err)
+	}
+	return taggedStruct("risc0.ReceiptClaim", []Digest{input, pre, post, output}, exitCode[:]), nil
+}
+
+// ImageID is the digest of the pre state, i.e. the image id of the guest
+func (c *ReceiptClaim) ImageID() (Digest, error) {
+	return maybePrunedDigest(&c.Pre, (*SystemState).Digest)
+}
+
+// OkClaimDigest is the claim digest of a successful execution of the image which commits to the journal
+func OkClaimDigest(imageId Digest, journal []byte) Digest {
+	post := taggedStruct("risc0.SystemState", []Digest{{}}, []uint32{0})
+	output := taggedStruct("risc0.Output", []Digest{sha256Digest(journal), {}}, nil)
+	return taggedStruct("risc0.ReceiptClaim", []Digest{{}, imageId, post, output}, []uint32{0, 0})
+}
+
+// Claim returns the claim of the Succinct or Groth16 receipt
+func (r *InnerReceipt) Claim() (*MaybePruned[*ReceiptClaim], error) {
+	switch {
+	case r.Succinct != nil:
+		return &r.Succinct.Claim, nil
+	case r.Groth16 != nil:
+		return &r.Groth16.Claim, nil
+	default:
+		return nil, logex.NewErrorf("unsupported receipt: %v", r.Type)
+	}
+}
+
+// VerifyClaim checks the claim of the receipt is a successful execution of the image which commits to the journal.
+// It doesn't verify the seal, see VerifyGroth16.
+func (r *Receipt) VerifyClaim(imageId Digest) error {
+	claim, err := r.Inner.Claim()
+	if err != nil {
+		return logex.Trace(err)
+	}
+	expected := OkClaimDigest(imageId, r.Journal.Bytes)
+	if claim.Pruned != nil {
+		if *claim.Pruned != expected {
+			return ErrClaimMismatch.Format(expected.Hex(), claim.Pruned.Hex())
+		}
+		return nil
+	}
+	value := *claim.Value
+	if value.Pre.Value != nil {
+		pre, err := value.ImageID()
+		if err != nil {
+			return logex.Trace(err)
+		}
+		if pre != imageId {
+			return ErrImageIdMismatch.Format(imageId.Hex(), pre.Hex())
+		}
+	}
+	if value.ExitCode.Halted == nil || *value.ExitCode.Halted != 0 {
+		return ErrUnexpectedExitCode.Format(value.ExitCode.String())
+	}
+	if output := value.Output.Value; output != nil && (*output).Val != nil {
+		if journal := (*(*output).Val).Journal.Value; journal != nil && !bytes.Equal(**journal, r.Journal.Bytes) {
+			want, got := sha256Digest(**journal), r.Journal.Digest()
+			return ErrJournalMismatch.Format(want.Hex(), got.Hex())
+		}
+	}
+	claimDigest, err := value.Digest()
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if claimDigest != expected {
+		return ErrClaimMismatch.Format(expected.Hex(), claimDigest.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package bonsai
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bincode"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+)
+
+func TestVerifyClaim(t *testing.T) {
+	defer test.New(t)
+	for _, receiptHex := range []string{testReceipt1, testReceipt2} {
+		data, err := hex.DecodeString(receiptHex)
+		test.Nil(err)
+		receipt, err := NewReceiptFromBincode(data)
+		test.Nil(err)
+		claim, err := receipt.Inner.Claim()
+		test.Nil(err)
+		imageId, err := (*claim.Value).ImageID()
+		test.Nil(err)
+		test.Nil(receipt.VerifyClaim(imageId))
+
+		otherImage := imageId
+		otherImage[0] ^= 1
+		test.True(logex.Equal(receipt.VerifyClaim(otherImage), ErrImageIdMismatch))
+
+		// a pruned claim is checked by its digest
+		claimDigest, err := (*claim.Value).Digest()
+		test.Nil(err)
+		pruned := &Receipt{Inner: receipt.Inner, Journal: receipt.Journal}
+		if pruned.Inner.Groth16 != nil {
+			pruned.Inner.Groth16 = &Groth16Receipt[*ReceiptClaim]{Claim: MaybePruned[*ReceiptClaim]{Type: 1, Pruned: &claimDigest}}
+		} else {
+			pruned.Inner.Succinct = &SuccinctReceipt[*ReceiptClaim]{Claim: MaybePruned[*ReceiptClaim]{Type: 1, Pruned: &claimDigest}}
+		}
+		test.Nil(pruned.VerifyClaim(imageId))
+		test.True(logex.Equal(pruned.VerifyClaim(otherImage), ErrClaimMismatch))
+
+		receipt.Journal.Bytes = append([]byte{1}, receipt.Journal.Bytes...)
+		test.True(logex.Equal(receipt.VerifyClaim(imageId), ErrJournalMismatch))
+
+		halted := uint32(1)
+		(*claim.Value).ExitCode = ExitCode{Type: 0, Halted: &halted}
+		test.True(logex.Equal(receipt.VerifyClaim(imageId), ErrUnexpectedExitCode))
+	}
+}
+
+// TestClaimDigestVectors checks the digests of the claim of test_receipt_2.hex, the claim digest
+// is the public input of its seal and the zero system state is SYSTEM_STATE_ZERO_DIGEST of risc0-ethereum
+func TestClaimDigestVectors(t *testing.T) {
+	defer test.New(t)
+	receipt := testGroth16Receipt()
+	claim := *receipt.Inner.Groth16.Claim.Value
+	output := *(*claim.Output.Value).Val
+
+	assumptions, err := maybePrunedDigest(&output.Assumptions, func(list *bincode.Collection[*MaybePruned[*Assumption]]) (Digest, error) {
+		test.Equal(len(*list), 0)
+		return taggedList("risc0.Assumptions", nil), nil
+	})
+	test.Nil(err)
+	test.Equal(assumptions, Digest{})
+
+	for _, item := range []struct {
+		digest func() (Digest, error)
+		want   string
+	}{
+		{func() (Digest, error) { return receipt.Journal.Digest(), nil }, "6798fbb5b9c021cf399b12f71dc7fd66346600313eea28e0c1eedcd8c45d7b7b"},
+		{output.Digest, "6e8d2eae5971fd18ccc99686489970a25b362c85d35061ac1ba96830500c73e1"},
+		{claim.ImageID, "83613a8beec226d1f29714530f1df791fa16c2c4dfcf22c50ab7edac59ca637f"},
+		{(*claim.Post.Value).Digest, "a3acc27117418996340b84e5a90f3ef4c49d22c79e44aad822ec9c313e1eb8e2"},
+		{claim.Digest, "8436c3e3ec019103edb6348c33df5f9cada0adebd805703b37591d9a47763a26"},
+	} {
+		digest, err := item.digest()
+		test.Nil(err)
+		test.Equal(digest.Hex(), item.want)
+	}
+}
+
+func TestExitCodePair(t *testing.T) {
+	defer test.New(t)
+	code := uint32(3)
+	pair, err := (&ExitCode{Type: 1, Paused: &code}).Pair()
+	test.Nil(err)
+	test.Equal(pair, [2]uint32{1, 3})
+	pair, err = (&ExitCode{Type: 3, SessionLimit: &struct{}{}}).Pair()
+	test.Nil(err)
+	test.Equal(pair, [2]uint32{2, 2})
+	_, err = (&ExitCode{Type: 4}).Pair()
+	test.NotNil(err)
+}
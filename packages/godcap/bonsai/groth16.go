@@ -1,8 +1,159 @@
 package bonsai
 
+import (
+	"math/big"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/groth16"
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/bn256"
+)
+
+var (
+	ErrSelectorMismatch = logex.Define("verifier selector mismatch: want %x, got %x")
+)
+
 func Groth16Encode(selector [4]byte, data []byte) []byte {
 	out := make([]byte, 4+len(data))
 	copy(out[:4], selector[:])
 	copy(out[4:], data)
 	return out
 }
+
+// Groth16VerifierParameters identifies the recursion circuit of risc0, see ControlID.sol of risc0-ethereum
+type Groth16VerifierParameters struct {
+	// Digest is recorded in the receipts, its first 4 bytes are the selector of the on-chain seal
+	Digest         Digest
+	ControlRoot    common.Hash
+	Bn254ControlID common.Hash
+}
+
+// Selector is the prefix of the seal expected by the RiscZeroVerifierRouter
+func (p *Groth16VerifierParameters) Selector() [4]byte {
+	var selector [4]byte
+	copy(selector[:], p.Digest.Bytes())
+	return selector
+}
+
+// GROTH16_VERIFIER_PARAMETERS are the parameters of risc0 v1.1 used by the guest
+var GROTH16_VERIFIER_PARAMETERS = &Groth16VerifierParameters{
+	Digest:         mustDigest("50bd1769093e74abda3711c315d84d78e3e282173f6304a33272d92abb590ef5"),
+	ControlRoot:    common.HexToHash("0x8b6dcf11d463ac455361b41fb3ed053febb817491bdea00fdb340e45013b852e"),
+	Bn254ControlID: common.HexToHash("0x05a022e1db38457fb510bc347b30eb8f8cf3eda95587653d0eac19e1f10d164e"),
+}
+
+// groth16Vk is the verifying key of the risc0 stark-to-snark circuit, see Groth16Verifier.sol of risc0-ethereum
+var groth16Vk = &groth16.VerifyingKey{
+	Alpha: groth16.MustG1(
+		"20491192805390485299153009773594534940189261866228447918068658471970481763042",
+		"9383485363053290200918347156157836566562967994039712273449902621266178545958",
+	),
+	Beta: groth16.MustG2(
+		"4252822878758300859123897981450591353533073413197771768651442665752259397132",
+		"6375614351688725206403948262868962793625744043794305715222011528459656738731",
+		"21847035105528745403288232691147584728191162732299865338377159692350059136679",
+		"10505242626370262277552901082094356697409835680220590971873171140371331206856",
+	),
+	Gamma: groth16.MustG2(
+		"11559732032986387107991004021392285783925812861821192530917403151452391805634",
+		"10857046999023057135944570762232829481370756359578518086990519993285655852781",
+		"4082367875863433681332203403145435568316851327593401208105741076214120093531",
+		"8495653923123431417604973247489272438418190587263600148770280649306958101930",
+	),
+	Delta: groth16.MustG2(
+		"1668323501672964604911431804142266013250380587483576094566949227275849579036",
+		"12043754404802191763554326994664886008979042643626290185762540825416902247219",
+		"7710631539206257456743780535472368339139328733484942210876916214502466455394",
+		"13740680757317479711909903993315946540841369848973133181051452051592786724563",
+	),
+	IC: []*bn256.G1{
+		groth16.MustG1(
+			"8446592859352799428420270221449902464741693648963397251242447530457567083492",
+			"1064796367193003797175961162477173481551615790032213185848276823815288302804",
+		),
+		groth16.MustG1(
+			"3179835575189816632597428042194253779818690147323192973511715175294048485951",
+			"20895841676865356752879376687052266198216014795822152491318012491767775979074",
+		),
+		groth16.MustG1(
+			"5332723250224941161709478398807683311971555792614491788690328996478511465287",
+			"21199491073419440416471372042641226693637837098357067793586556692319371762571",
+		),
+		groth16.MustG1(
+			"12457994489566736295787256452575216703923664299075106359829199968023158780583",
+			"19706766271952591897761291684837117091856807401404423804318744964752784280790",
+		),
+		groth16.MustG1(
+			"19617808913178163826953378459323299110911217259216006187355745713323154132237",
+			"21663537384585072695701846972542344484111393047775983928357046779215877070466",
+		),
+		groth16.MustG1(
+			"6834578911681792552110317589222010969491336870276623105249474534788043166867",
+			"15060583660288623605191393599883223885678013570733629274538391874953353488393",
+		),
+	},
+}
+
+func mustDigest(s string) Digest {
+	d, err := DigestFromHex(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// splitDigest splits the digest into two 128 bits field elements, the same as RiscZeroGroth16Verifier
+func splitDigest(d []byte) (*big.Int, *big.Int) {
+	reversed := make([]byte, len(d))
+	for i := range d {
+		reversed[len(d)-1-i] = d[i]
+	}
+	return new(big.Int).SetBytes(reversed[16:]), new(big.Int).SetBytes(reversed[:16])
+}
+
+// groth16PublicInputs are the control root, the claim digest and the bn254 control id
+func groth16PublicInputs(claimDigest Digest, params *Groth16VerifierParameters) []*big.Int {
+	controlRoot0, controlRoot1 := splitDigest(params.ControlRoot[:])
+	claim0, claim1 := splitDigest(claimDigest.Bytes())
+	return []*big.Int{controlRoot0, controlRoot1, claim0, claim1, params.Bn254ControlID.Big()}
+}
+
+// VerifyGroth16 verifies the seal of a Groth16Receipt against the claim digest.
+// The seal is the a, b, c points in the encoding of the bn254 precompiles.
+func VerifyGroth16(seal []byte, claimDigest Digest, params *Groth16VerifierParameters) error {
+	if params == nil {
+		params = GROTH16_VERIFIER_PARAMETERS
+	}
+	if err := groth16Vk.Verify(seal, groth16PublicInputs(claimDigest, params)); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+// VerifyGroth16Journal verifies the seal proves a successful execution of the image with the journal
+func VerifyGroth16Journal(seal []byte, imageId Digest, journal []byte, params *Groth16VerifierParameters) error {
+	return VerifyGroth16(seal, OkClaimDigest(imageId, journal), params)
+}
+
+// VerifyGroth16 verifies the Groth16 seal of the receipt.
+// The claim must be a successful execution of the image which commits to the journal of the receipt.
+func (r *Receipt) VerifyGroth16(imageId Digest, params *Groth16VerifierParameters) error {
+	receipt := r.Inner.Groth16
+	if receipt == nil {
+		return logex.NewErrorf("not a groth16 receipt: %v", r.Inner.Type)
+	}
+	if params == nil {
+		params = GROTH16_VERIFIER_PARAMETERS
+	}
+	if receipt.VerifierParameters != params.Digest {
+		return ErrSelectorMismatch.Format(params.Digest.Bytes()[:4], receipt.VerifierParameters.Bytes()[:4])
+	}
+	if err := r.VerifyClaim(imageId); err != nil {
+		return logex.Trace(err)
+	}
+	expected := OkClaimDigest(imageId, r.Journal.Bytes)
+	if err := VerifyGroth16(receipt.Seal, expected, params); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
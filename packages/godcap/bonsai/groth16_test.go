@@ -0,0 +1,94 @@
+package bonsai
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/groth16"
+	"github.com/automata-network/dcap-sdk/packages/godcap/testutil"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+)
+
+// useTestVerifyingKey replaces the verifying key with a generated one, and returns a function
+// which simulates the seal of a claim with the trapdoor
+func useTestVerifyingKey(t *testing.T, params *Groth16VerifierParameters) func(claimDigest Digest) []byte {
+	vk, td, err := testutil.NewGroth16TestKey(5)
+	test.Nil(err)
+	old := groth16Vk
+	groth16Vk = vk
+	t.Cleanup(func() { groth16Vk = old })
+	return func(claimDigest Digest) []byte {
+		seal, err := td.Prove(groth16PublicInputs(claimDigest, params))
+		test.Nil(err)
+		return seal
+	}
+}
+
+func testGroth16Receipt() *Receipt {
+	data, err := hex.DecodeString(testReceipt2)
+	test.Nil(err)
+	receipt, err := NewReceiptFromBincode(data)
+	test.Nil(err)
+	return receipt
+}
+
+func TestOkClaimDigest(t *testing.T) {
+	defer test.New(t)
+	receipt := testGroth16Receipt()
+	claim := *receipt.Inner.Groth16.Claim.Value
+	imageId, err := claim.ImageID()
+	test.Nil(err)
+	claimDigest, err := claim.Digest()
+	test.Nil(err)
+	test.Equal(OkClaimDigest(imageId, receipt.Journal.Bytes), claimDigest)
+	test.Equal(receipt.Inner.Groth16.VerifierParameters, GROTH16_VERIFIER_PARAMETERS.Digest)
+	test.Equal(GROTH16_VERIFIER_PARAMETERS.Selector(), [4]byte{0x50, 0xbd, 0x17, 0x69})
+}
+
+func TestVerifyGroth16Receipt(t *testing.T) {
+	defer test.New(t)
+	// the seal of the fixture is proved by Bonsai, it's checked with the production verifying key
+	receipt := testGroth16Receipt()
+	imageId, err := (*receipt.Inner.Groth16.Claim.Value).ImageID()
+	test.Nil(err)
+	test.Nil(receipt.VerifyGroth16(imageId, GROTH16_VERIFIER_PARAMETERS))
+	test.Nil(VerifyGroth16Journal(receipt.Inner.Groth16.Seal, imageId, receipt.Journal.Bytes, nil))
+
+	journal := append([]byte{}, receipt.Journal.Bytes...)
+	journal[0] ^= 1
+	test.True(logex.Equal(VerifyGroth16Journal(receipt.Inner.Groth16.Seal, imageId, journal, nil), groth16.ErrVerification))
+}
+
+func TestVerifyGroth16(t *testing.T) {
+	defer test.New(t)
+	params := GROTH16_VERIFIER_PARAMETERS
+	prove := useTestVerifyingKey(t, params)
+
+	receipt := testGroth16Receipt()
+	imageId, err := (*receipt.Inner.Groth16.Claim.Value).ImageID()
+	test.Nil(err)
+	journal := []byte(receipt.Journal.Bytes)
+	seal := prove(OkClaimDigest(imageId, journal))
+
+	test.Nil(VerifyGroth16Journal(seal, imageId, journal, params))
+	receipt.Inner.Groth16.Seal = seal
+	test.Nil(receipt.VerifyGroth16(imageId, nil))
+
+	// the seal doesn't prove another journal or image
+	tampered := append([]byte{}, journal...)
+	tampered[len(tampered)-1] ^= 1
+	test.True(logex.Equal(VerifyGroth16Journal(seal, imageId, tampered, params), groth16.ErrVerification))
+	otherImage := imageId
+	otherImage[0] ^= 1
+	test.True(logex.Equal(VerifyGroth16Journal(seal, otherImage, journal, params), groth16.ErrVerification))
+	test.True(logex.Equal(receipt.VerifyGroth16(otherImage, nil), ErrImageIdMismatch))
+
+	receipt.Journal.Bytes = tampered
+	test.True(logex.Equal(receipt.VerifyGroth16(imageId, nil), ErrJournalMismatch))
+
+	test.True(logex.Equal(VerifyGroth16(seal[:255], OkClaimDigest(imageId, journal), params), groth16.ErrInvalidProof))
+	badPoint := append([]byte{}, seal...)
+	badPoint[63] ^= 1
+	test.True(logex.Equal(VerifyGroth16(badPoint, OkClaimDigest(imageId, journal), params), groth16.ErrInvalidProof))
+}
@@ -0,0 +1,169 @@
+package bonsai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chzyer/logex"
+)
+
+// ErrAPI is returned for the non-2xx responses of Bonsai, see StatusCode
+var ErrAPI = logex.Define("bonsai api error: %v %v")
+
+const (
+	// maxRetryBackoff caps the exponential backoff and the Retry-After of the responses
+	maxRetryBackoff = 30 * time.Second
+)
+
+// apiErrorBody is the error body of Bonsai, the raw body is used if it's not in this format
+type apiErrorBody struct {
+	ErrorCode string `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
+
+func newAPIError(statusCode int, body []byte) error {
+	msg := strings.TrimSpace(string(body))
+	var errBody apiErrorBody
+	if json.Unmarshal(body, &errBody) == nil && errBody.ErrorMsg != "" {
+		msg = errBody.ErrorMsg
+		if errBody.ErrorCode != "" {
+			msg = errBody.ErrorCode + ": " + msg
+		}
+	}
+	return ErrAPI.Format(statusCode, msg).SetCode(statusCode)
+}
+
+// StatusCode returns the http status of an ErrAPI, 0 for the other errors
+func StatusCode(err error) int {
+	if !logex.Equal(err, ErrAPI) {
+		return 0
+	}
+	if e, ok := err.(interface{ GetCode() int }); ok {
+		return e.GetCode()
+	}
+	return 0
+}
+
+// isIdempotent reports whether the request has the same effect if it's sent again
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// isRetryable reports whether the request may succeed if sent again. The requests which aren't idempotent,
+// e.g. sessions/create, are only retried if they weren't processed, otherwise a session would be duplicated.
+func isRetryable(method string, err error) bool {
+	var dnsErr *net.DNSError
+	if errors.Is(err, syscall.ECONNREFUSED) || (errors.As(err, &dnsErr) && dnsErr.IsTemporary) {
+		// the request wasn't sent
+		return true
+	}
+	idempotent := isIdempotent(method)
+	switch StatusCode(err) {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	case 0:
+		if !idempotent {
+			return false
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true
+		}
+		return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+	}
+	return false
+}
+
+// backoff returns the delay before the retry, with jitter so the clients don't retry in lockstep
+func (c *Client) backoff(retry int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, maxRetryBackoff)
+	}
+	delay := time.Duration(c.cfg.RetryBackoffMs) * time.Millisecond << retry
+	if delay <= 0 || delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// do sends the request with the retries of Config.MaxRetries, the body is nil or replayed on each attempt
+func (c *Client) do(ctx context.Context, method string, url string, body []byte, header http.Header) (int, []byte, error) {
+	for retry := 0; ; retry++ {
+		statusCode, respBody, retryAfter, err := c.doOnce(ctx, method, url, body, header)
+		if err == nil {
+			return statusCode, respBody, nil
+		}
+		if ctx.Err() != nil {
+			return statusCode, nil, logex.Trace(ctx.Err())
+		}
+		if retry >= c.cfg.MaxRetries || !isRetryable(method, err) {
+			return statusCode, nil, logex.Trace(err)
+		}
+		delay := c.backoff(retry, retryAfter)
+		logex.Infof("retry %v %v in %v: %v", method, url, delay, err)
+		select {
+		case <-ctx.Done():
+			return statusCode, nil, logex.Trace(ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (c *Client) doOnce(ctx context.Context, method string, url string, body []byte, header http.Header) (int, []byte, time.Duration, error) {
+	if c.cfg.TimeoutSecs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.cfg.TimeoutSecs)*time.Second)
+		defer cancel()
+	}
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return 0, nil, 0, logex.Trace(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	httpResponse, err := c.httpClient().Do(req)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	defer httpResponse.Body.Close()
+	httpBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return httpResponse.StatusCode, nil, 0, err
+	}
+	if httpResponse.StatusCode/100 != 2 {
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(httpResponse.Header.Get("Retry-After")); err == nil && secs > 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return httpResponse.StatusCode, nil, retryAfter, newAPIError(httpResponse.StatusCode, httpBody)
+	}
+	return httpResponse.StatusCode, httpBody, 0, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.cfg.HTTPClient != nil {
+		return c.cfg.HTTPClient
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,107 @@
+package bonsai
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+)
+
+func TestClientRetry(t *testing.T) {
+	defer test.New(t)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/sessions/status/flaky":
+			if n < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"status":"RUNNING","state":"Executor"}`))
+		case "/sessions/create":
+			if n < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		case "/sessions/status/bad":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error_code":"InvalidSession","error_msg":"session not found"}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("upstream down"))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{Url: server.URL, ApiKey: "key", RetryBackoffMs: 1, HTTPClient: server.Client()})
+	test.Nil(err)
+	ctx := context.Background()
+
+	status, err := client.Session("flaky").Status(ctx)
+	test.Nil(err)
+	test.Equal(status.State, "Executor")
+	test.Equal(atomic.LoadInt32(&requests), int32(3))
+
+	// the client errors aren't retried
+	atomic.StoreInt32(&requests, 0)
+	_, err = client.Session("bad").Status(ctx)
+	test.True(logex.Equal(err, ErrAPI))
+	test.Equal(StatusCode(err), http.StatusBadRequest)
+	test.True(strings.Contains(err.Error(), "InvalidSession: session not found"))
+	test.Equal(atomic.LoadInt32(&requests), int32(1))
+
+	atomic.StoreInt32(&requests, 0)
+	_, err = client.Session("down").Status(ctx)
+	test.Equal(StatusCode(err), http.StatusBadGateway)
+	test.True(strings.Contains(err.Error(), "upstream down"))
+	test.Equal(atomic.LoadInt32(&requests), int32(4))
+
+	// the session may be created by the failed request, only the rejected requests are sent again
+	atomic.StoreInt32(&requests, 0)
+	_, _, err = client.do(ctx, http.MethodPost, server.URL+"/sessions/create", []byte("{}"), nil)
+	test.Equal(StatusCode(err), http.StatusBadGateway)
+	test.Equal(atomic.LoadInt32(&requests), int32(2))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.Session("flaky").Status(canceled)
+	test.True(logex.Equal(err, context.Canceled))
+}
+
+func TestIsRetryable(t *testing.T) {
+	defer test.New(t)
+	refused := &url.Error{Op: "Post", URL: "http://bonsai", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}
+	timeout := &url.Error{Op: "Post", URL: "http://bonsai", Err: context.DeadlineExceeded}
+	reset := &url.Error{Op: "Post", URL: "http://bonsai", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}
+	for _, item := range []struct {
+		method string
+		err    error
+		retry  bool
+	}{
+		{http.MethodPost, refused, true},
+		{http.MethodPost, newAPIError(http.StatusTooManyRequests, nil), true},
+		{http.MethodPost, newAPIError(http.StatusServiceUnavailable, nil), true},
+		{http.MethodPost, newAPIError(http.StatusInternalServerError, nil), false},
+		{http.MethodPost, timeout, false},
+		{http.MethodPost, reset, false},
+		{http.MethodGet, newAPIError(http.StatusInternalServerError, nil), true},
+		{http.MethodGet, timeout, true},
+		{http.MethodGet, reset, true},
+		{http.MethodGet, &url.Error{Op: "Get", URL: "http://bonsai", Err: errors.New("tls: bad certificate")}, false},
+		{http.MethodPut, newAPIError(http.StatusBadRequest, nil), false},
+	} {
+		test.Equal(isRetryable(item.method, item.err), item.retry)
+	}
+}
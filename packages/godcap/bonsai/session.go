@@ -0,0 +1,111 @@
+package bonsai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/chzyer/logex"
+)
+
+// ErrSessionFailed is returned by the polls if the session isn't RUNNING nor SUCCEEDED
+var ErrSessionFailed = logex.Define("session %v %v: %v")
+
+// Stop aborts the running session, its status becomes ABORTED
+func (s *Session) Stop(ctx context.Context) error {
+	if _, err := s.client.api(ctx, http.MethodGet, fmt.Sprintf("sessions/stop/%v", s.uuid), nil, nil); err != nil {
+		return logex.Trace(err, "StopSession")
+	}
+	return nil
+}
+
+// Logs returns the stdout and stderr of the guest execution
+func (s *Session) Logs(ctx context.Context) (string, error) {
+	_, logs, err := s.client.apiRaw(ctx, http.MethodGet, fmt.Sprintf("sessions/logs/%v", s.uuid), nil)
+	if err != nil {
+		return "", logex.Trace(err, "SessionLogs")
+	}
+	return string(logs), nil
+}
+
+// ReceiptDownload is the response of the receipt query of a session
+type ReceiptDownload struct {
+	Url string `json:"url"`
+}
+
+// Receipt downloads the succinct receipt of the succeeded session
+func (s *Session) Receipt(ctx context.Context) (*Receipt, []byte, error) {
+	return s.client.DownloadReceipt(ctx, s.uuid)
+}
+
+// DownloadReceipt downloads the succinct receipt of a succeeded session by its id
+func (c *Client) DownloadReceipt(ctx context.Context, sessionId string) (*Receipt, []byte, error) {
+	var response ReceiptDownload
+	if _, err := c.api(ctx, http.MethodGet, fmt.Sprintf("receipts/%v", sessionId), nil, &response); err != nil {
+		return nil, nil, logex.Trace(err, "ReceiptDownload")
+	}
+	data, err := c.s3(ctx, http.MethodGet, response.Url, nil)
+	if err != nil {
+		return nil, nil, logex.Trace(err)
+	}
+	receipt, err := NewReceiptFromBincode(data)
+	if err != nil {
+		return nil, nil, logex.Trace(err)
+	}
+	return receipt, data, nil
+}
+
+// VersionInfo lists the versions supported by Bonsai
+type VersionInfo struct {
+	/// Supported versions of the risc0-zkvm crate
+	Risc0Zkvm []string `json:"risc0_zkvm"`
+}
+
+// Supports reports whether the version is supported, e.g. Config.Version
+func (v *VersionInfo) Supports(version string) bool {
+	for _, item := range v.Risc0Zkvm {
+		if item == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Version returns the versions supported by Bonsai
+func (c *Client) Version(ctx context.Context) (*VersionInfo, error) {
+	var response VersionInfo
+	if _, err := c.api(ctx, http.MethodGet, "version", nil, &response); err != nil {
+		return nil, logex.Trace(err, "Version")
+	}
+	return &response, nil
+}
+
+// Quotas are the limits and the usage of the api key
+type Quotas struct {
+	/// Executor cycle limit, in millions of cycles
+	ExecCycleLimit int64 `json:"exec_cycle_limit"`
+	/// Max parallel proving units
+	ConcurrentProofs int64 `json:"concurrent_proofs"`
+	/// Max total proving cycle budget
+	CycleBudget int64 `json:"cycle_budget"`
+	/// Total proving cycle usage
+	CycleUsage int64 `json:"cycle_usage"`
+	/// Dedicated Executor
+	DedicatedExecutor int32 `json:"dedicated_executor"`
+	/// Dedicated GPU
+	DedicatedGpu int32 `json:"dedicated_gpu"`
+}
+
+// RemainingCycles returns the cycles left in the budget
+func (q *Quotas) RemainingCycles() int64 {
+	return q.CycleBudget - q.CycleUsage
+}
+
+// Quotas returns the limits and the usage of the api key
+func (c *Client) Quotas(ctx context.Context) (*Quotas, error) {
+	var response Quotas
+	if _, err := c.api(ctx, http.MethodGet, "user/quotas", nil, &response); err != nil {
+		return nil, logex.Trace(err, "Quotas")
+	}
+	return &response, nil
+}
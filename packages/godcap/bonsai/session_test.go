@@ -0,0 +1,83 @@
+package bonsai
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+)
+
+func TestSessionManagement(t *testing.T) {
+	defer test.New(t)
+	receipt, err := hex.DecodeString(strings.TrimSpace(testReceipt1))
+	test.Nil(err)
+
+	stopped := map[string]bool{}
+	var server *httptest.Server
+	reply := func(w http.ResponseWriter, val interface{}) {
+		json.NewEncoder(w).Encode(val)
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "key" && !strings.HasPrefix(r.URL.Path, "/s3/") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch path := r.URL.Path; {
+		case strings.HasPrefix(path, "/sessions/stop/"):
+			stopped[strings.TrimPrefix(path, "/sessions/stop/")] = true
+		case path == "/sessions/logs/failed":
+			w.Write([]byte("panicked at guest/src/main.rs"))
+		case path == "/sessions/status/failed":
+			reply(w, &SessionStatusRes{Status: "FAILED", ErrorMsg: "guest panicked"})
+		case path == "/receipts/done":
+			reply(w, &ReceiptDownload{Url: server.URL + "/s3/receipt"})
+		case path == "/s3/receipt":
+			w.Write(receipt)
+		case path == "/version":
+			reply(w, &VersionInfo{Risc0Zkvm: []string{"1.2.0", "2.0.1"}})
+		case path == "/user/quotas":
+			reply(w, &Quotas{ExecCycleLimit: 100, ConcurrentProofs: 2, CycleBudget: 1000, CycleUsage: 400})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{Url: server.URL, ApiKey: "key", PollIntervalSecs: 1})
+	test.Nil(err)
+	ctx := context.Background()
+
+	test.Nil(client.Session("running").Stop(ctx))
+	test.True(stopped["running"])
+
+	failed := client.Session("failed")
+	_, err = failed.Poll(ctx, client.PollInterval())
+	test.True(logex.Equal(err, ErrSessionFailed))
+	test.True(strings.Contains(err.Error(), "guest panicked"))
+	logs, err := failed.Logs(ctx)
+	test.Nil(err)
+	test.Equal(logs, "panicked at guest/src/main.rs")
+
+	downloaded, data, err := client.Session("done").Receipt(ctx)
+	test.Nil(err)
+	test.Equal(data, receipt)
+	test.NotNil(downloaded.Inner.Succinct)
+	_, _, err = client.DownloadReceipt(ctx, "unknown")
+	test.Equal(StatusCode(err), http.StatusNotFound)
+
+	version, err := client.Version(ctx)
+	test.Nil(err)
+	test.True(version.Supports(client.cfg.Version))
+	test.False(version.Supports("0.1.0"))
+
+	quotas, err := client.Quotas(ctx)
+	test.Nil(err)
+	test.Equal(quotas.ConcurrentProofs, int64(2))
+	test.Equal(quotas.RemainingCycles(), int64(600))
+}
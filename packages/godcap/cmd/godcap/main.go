@@ -1,17 +1,21 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/automata-network/dcap-sdk/packages/godcap"
+	"github.com/automata-network/dcap-sdk/packages/godcap/sigstruct"
 	"github.com/chzyer/flagly"
 	"github.com/chzyer/logex"
 )
 
 type GoDcap struct {
-	Config   *GoDcapConfig   `flagly:"handler"`
-	Examples *GoDcapExamples `flagly:"handler"`
+	Config    *GoDcapConfig    `flagly:"handler"`
+	Examples  *GoDcapExamples  `flagly:"handler"`
+	Sigstruct *GoDcapSigstruct `flagly:"handler"`
+	Proof     *GoDcapProof     `flagly:"handler"`
 }
 
 type GoDcapConfig struct {
@@ -34,6 +38,30 @@ func (g *GoDcapConfigContract) FlaglyHandle() error {
 	return nil
 }
 
+// GoDcapSigstruct prints the expected enclave measurements of a SIGSTRUCT file
+type GoDcapSigstruct struct {
+	Path string `type:"[0]"`
+}
+
+func (g *GoDcapSigstruct) FlaglyHandle() error {
+	if g.Path == "" {
+		return flagly.ErrShowUsage
+	}
+	sig, err := sigstruct.ParseFile(g.Path)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if err := sig.Verify(); err != nil {
+		return logex.Trace(err, "invalid signature")
+	}
+	data, err := json.MarshalIndent(sig.Measurements(), "", "  ")
+	if err != nil {
+		return logex.Trace(err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
 func main() {
 	if err := flagly.RunByArgs(&GoDcap{}, os.Args); err != nil {
 		logex.Fatal(err)
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/zkdcap"
+	"github.com/chzyer/flagly"
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GoDcapProof converts the encodings of zkdcap.ZkProof
+type GoDcapProof struct {
+	Encode  *GoDcapProofEncode  `flagly:"handler"`
+	Decode  *GoDcapProofDecode  `flagly:"handler"`
+	Inspect *GoDcapProofInspect `flagly:"handler"`
+}
+
+// readProofFile reads the proof from the path or stdin if path is "-".
+// The binary encoding can also be given in hex.
+func readProofFile(path string) (*zkdcap.ZkProof, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	data = bytes.TrimSpace(data)
+	if decoded, err := hex.DecodeString(string(bytes.TrimPrefix(data, []byte("0x")))); err == nil {
+		data = decoded
+	}
+	proof, err := zkdcap.DecodeZkProof(data)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return proof, nil
+}
+
+// GoDcapProofEncode converts a proof to the binary encoding
+type GoDcapProofEncode struct {
+	Out  string `desc:"write the raw bytes to the file instead of printing hex"`
+	Path string `type:"[0]"`
+}
+
+func (g *GoDcapProofEncode) FlaglyHandle() error {
+	if g.Path == "" {
+		return flagly.ErrShowUsage
+	}
+	proof, err := readProofFile(g.Path)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if g.Out != "" {
+		return logex.Trace(os.WriteFile(g.Out, data, 0644))
+	}
+	fmt.Println(hex.EncodeToString(data))
+	return nil
+}
+
+// GoDcapProofDecode converts a proof to the JSON encoding
+type GoDcapProofDecode struct {
+	Path string `type:"[0]"`
+}
+
+func (g *GoDcapProofDecode) FlaglyHandle() error {
+	if g.Path == "" {
+		return flagly.ErrShowUsage
+	}
+	proof, err := readProofFile(g.Path)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	data, err := json.MarshalIndent(proof, "", "  ")
+	if err != nil {
+		return logex.Trace(err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// GoDcapProofInspect prints the metadata of a proof and its verified output
+type GoDcapProofInspect struct {
+	Path string `type:"[0]"`
+}
+
+type proofSummary struct {
+	Type            zkdcap.ZkType `json:"type"`
+	Mode            string        `json:"mode"`
+	OnChain         bool          `json:"on_chain"`
+	ProgramID       string        `json:"program_id"`
+	InputDigest     common.Hash   `json:"input_digest"`
+	CollateralBlock uint64        `json:"collateral_block"`
+	OutputSize      int           `json:"output_size"`
+	ProofSize       int           `json:"proof_size"`
+	Selector        string        `json:"selector,omitempty"`
+	VerifiedOutput  interface{}   `json:"verified_output"`
+}
+
+func (g *GoDcapProofInspect) FlaglyHandle() error {
+	if g.Path == "" {
+		return flagly.ErrShowUsage
+	}
+	proof, err := readProofFile(g.Path)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	summary := &proofSummary{
+		Type:            proof.Type,
+		Mode:            proof.Mode.String(),
+		OnChain:         proof.Mode.OnChain(),
+		ProgramID:       proof.ProgramID,
+		InputDigest:     proof.InputDigest,
+		CollateralBlock: proof.CollateralBlock,
+		OutputSize:      len(proof.Output),
+		ProofSize:       len(proof.Proof),
+	}
+	if proof.Mode.OnChain() {
+		summary.Selector = hex.EncodeToString(proof.Proof[:4])
+	}
+	if output, err := proof.VerifiedOutput(); err == nil {
+		summary.VerifiedOutput = output
+	} else {
+		summary.VerifiedOutput = err.Error()
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return logex.Trace(err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,164 @@
+package eat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/automata-network/dcap-sdk/packages/godcap/zkdcap"
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EAT_PROFILE identifies the claims set issued by godcap
+const EAT_PROFILE = "tag:ata.network,2025:godcap-attestation-result"
+
+const (
+	METHOD_ON_CHAIN = "on-chain"
+	METHOD_RISC0    = "risc0"
+	METHOD_SP1      = "sp1"
+)
+
+const (
+	TEE_SGX = "SGX"
+	TEE_TDX = "TDX"
+)
+
+// Claims is the payload of an attestation result token
+type Claims struct {
+	Issuer    string `json:"iss,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Audience  string `json:"aud,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	Expiry    int64  `json:"exp,omitempty"`
+	ID        string `json:"jti,omitempty"`
+	Nonce     string `json:"eat_nonce,omitempty"`
+	Profile   string `json:"eat_profile,omitempty"`
+
+	TeeType      string        `json:"tee_type"`
+	QuoteVersion uint16        `json:"quote_version"`
+	TcbStatus    string        `json:"tcb_status"`
+	AdvisoryIDs  []string      `json:"advisory_ids,omitempty"`
+	Fmspc        string        `json:"fmspc"`
+	Measurements *Measurements `json:"measurements"`
+	Verification *Verification `json:"verification"`
+}
+
+// Measurements of the attested enclave or trust domain, all values are hex encoded
+type Measurements struct {
+	// SGX
+	MrEnclave  string `json:"mr_enclave,omitempty"`
+	MrSigner   string `json:"mr_signer,omitempty"`
+	IsvProdId  uint16 `json:"isv_prod_id,omitempty"`
+	IsvSvn     uint16 `json:"isv_svn,omitempty"`
+	Attributes string `json:"attributes,omitempty"`
+	MiscSelect uint32 `json:"misc_select,omitempty"`
+
+	// TDX
+	TeeTcbSvn     string   `json:"tee_tcb_svn,omitempty"`
+	MrSeam        string   `json:"mr_seam,omitempty"`
+	TdAttributes  string   `json:"td_attributes,omitempty"`
+	Xfam          string   `json:"xfam,omitempty"`
+	MrTd          string   `json:"mr_td,omitempty"`
+	MrConfigId    string   `json:"mr_config_id,omitempty"`
+	MrOwner       string   `json:"mr_owner,omitempty"`
+	MrOwnerConfig string   `json:"mr_owner_config,omitempty"`
+	Rtmrs         []string `json:"rtmrs,omitempty"`
+
+	ReportData string `json:"report_data"`
+}
+
+// Verification describes how the quote was verified
+type Verification struct {
+	// one of METHOD_ON_CHAIN, METHOD_RISC0 and METHOD_SP1
+	Method  string `json:"method"`
+	ChainId int64  `json:"chain_id,omitempty"`
+	// transaction of the on-chain verification, empty for a simulated call
+	TxHash string `json:"tx_hash,omitempty"`
+	// SHA-256 of the zk proof
+	ProofDigest string `json:"proof_digest,omitempty"`
+}
+
+// OnChainVerification describes a verification by the attestation contract,
+// txHash can be the zero hash if the quote was checked with a simulated call
+func OnChainVerification(chainId int64, txHash common.Hash) *Verification {
+	v := &Verification{Method: METHOD_ON_CHAIN, ChainId: chainId}
+	if txHash != (common.Hash{}) {
+		v.TxHash = txHash.Hex()
+	}
+	return v
+}
+
+// ZkVerification describes a verification by a zk proof
+func ZkVerification(proof *zkdcap.ZkProof) (*Verification, error) {
+	var method string
+	switch proof.Type {
+	case zkdcap.ZkTypeRiscZero:
+		method = METHOD_RISC0
+	case zkdcap.ZkTypeSuccinct:
+		method = METHOD_SP1
+	default:
+		return nil, logex.NewErrorf("unknown zkType: %v", proof.Type)
+	}
+	digest := sha256.Sum256(proof.Proof)
+	return &Verification{Method: method, ProofDigest: hex.EncodeToString(digest[:])}, nil
+}
+
+// NewClaims builds the claims from the verified output of a quote
+func NewClaims(output *parser.VerifiedOutput, verification *Verification) (*Claims, error) {
+	claims := &Claims{
+		Profile:      EAT_PROFILE,
+		QuoteVersion: output.QuoteVersion,
+		TcbStatus:    output.TcbStatus.String(),
+		AdvisoryIDs:  output.AdvisoryIDs,
+		Fmspc:        output.FmspcHex(),
+		Verification: verification,
+	}
+	if output.IsTdx() {
+		report, err := output.TdReport()
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		claims.TeeType = TEE_TDX
+		claims.Measurements = tdxMeasurements(report)
+	} else {
+		report, err := output.EnclaveReport()
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		claims.TeeType = TEE_SGX
+		claims.Measurements = sgxMeasurements(report)
+	}
+	return claims, nil
+}
+
+func sgxMeasurements(report *parser.EnclaveReport) *Measurements {
+	return &Measurements{
+		MrEnclave:  hex.EncodeToString(report.MrEnclave[:]),
+		MrSigner:   hex.EncodeToString(report.MrSigner[:]),
+		IsvProdId:  report.IsvProdId,
+		IsvSvn:     report.IsvSvn,
+		Attributes: hex.EncodeToString(report.Attributes[:]),
+		MiscSelect: report.MiscSelect,
+		ReportData: hex.EncodeToString(report.ReportData[:]),
+	}
+}
+
+func tdxMeasurements(report *parser.TdReport10) *Measurements {
+	m := &Measurements{
+		TeeTcbSvn:     hex.EncodeToString(report.TeeTcbSvn[:]),
+		MrSeam:        hex.EncodeToString(report.MrSeam[:]),
+		TdAttributes:  hex.EncodeToString(report.TdAttributes[:]),
+		Xfam:          hex.EncodeToString(report.Xfam[:]),
+		MrTd:          hex.EncodeToString(report.MrTd[:]),
+		MrConfigId:    hex.EncodeToString(report.MrConfigId[:]),
+		MrOwner:       hex.EncodeToString(report.MrOwner[:]),
+		MrOwnerConfig: hex.EncodeToString(report.MrOwnerConfig[:]),
+		ReportData:    hex.EncodeToString(report.ReportData[:]),
+	}
+	for _, rtmr := range report.Rtmr {
+		m.Rtmrs = append(m.Rtmrs, hex.EncodeToString(rtmr[:]))
+	}
+	return m
+}
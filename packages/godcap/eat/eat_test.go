@@ -0,0 +1,88 @@
+package eat
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/automata-network/dcap-sdk/packages/godcap/zkdcap"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+)
+
+// journal of a RISC Zero proof for a TDX quote
+//
+//go:embed test_zkoutput.hex
+var testZkOutput string
+
+func testClaims() *Claims {
+	output, err := hex.DecodeString(testZkOutput)
+	test.Nil(err)
+	proof := &zkdcap.ZkProof{Type: zkdcap.ZkTypeRiscZero, Output: output, Proof: []byte("seal")}
+	verifiedOutput, err := proof.VerifiedOutput()
+	test.Nil(err)
+	verification, err := ZkVerification(proof)
+	test.Nil(err)
+	claims, err := NewClaims(verifiedOutput, verification)
+	test.Nil(err)
+	return claims
+}
+
+func TestNewClaims(t *testing.T) {
+	defer test.New(t)
+	claims := testClaims()
+	test.Equal(claims.TeeType, TEE_TDX)
+	test.Equal(claims.QuoteVersion, uint16(4))
+	test.Equal(claims.TcbStatus, parser.TcbUnrecognized.String())
+	test.Equal(claims.Fmspc, "90c06f000000")
+	test.Equal(len(claims.Measurements.Rtmrs), 4)
+	test.Equal(claims.Measurements.MrTd[:8], "f2dd2696")
+	test.Equal(claims.Verification.Method, METHOD_RISC0)
+}
+
+func TestToken(t *testing.T) {
+	defer test.New(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.Nil(err)
+	now := time.Unix(1700000000, 0)
+	issuer := &Issuer{Key: key, KeyID: "k1", Name: "godcap", Now: func() time.Time { return now }}
+	verifier := &Verifier{
+		Keys:   map[string]*ecdsa.PublicKey{"k1": &key.PublicKey},
+		Issuer: "godcap",
+		Now:    func() time.Time { return now.Add(time.Minute) },
+	}
+
+	claims := testClaims()
+	claims.Audience = "svc"
+	token, err := issuer.Issue(claims)
+	test.Nil(err)
+	verified, err := verifier.Verify(token)
+	test.Nil(err)
+	test.Equal(verified.Measurements, claims.Measurements)
+	test.Equal(verified.IssuedAt, now.Unix())
+
+	// tampered payload
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + parts[1][:len(parts[1])-2] + "AA." + parts[2]
+	_, err = verifier.Verify(tampered)
+	test.True(logex.Equal(err, ErrInvalidSignature))
+
+	verifier.Audience = "other"
+	_, err = verifier.Verify(token)
+	test.True(logex.Equal(err, ErrAudienceMismatch))
+	verifier.Audience = ""
+
+	verifier.Now = func() time.Time { return now.Add(2 * time.Hour) }
+	_, err = verifier.Verify(token)
+	test.True(logex.Equal(err, ErrTokenExpired))
+
+	other := &Verifier{Keys: map[string]*ecdsa.PublicKey{"k2": &key.PublicKey}}
+	_, err = other.Verify(token)
+	test.True(logex.Equal(err, ErrUnknownKey))
+}
@@ -0,0 +1,211 @@
+package eat
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/chzyer/logex"
+)
+
+var (
+	ErrMalformedToken   = logex.Define("malformed token")
+	ErrUnsupportedAlg   = logex.Define("unsupported alg: %v")
+	ErrUnknownKey       = logex.Define("unknown key id: %q")
+	ErrInvalidSignature = logex.Define("invalid token signature")
+	ErrTokenExpired     = logex.Define("token expired at %v")
+	ErrTokenNotYetValid = logex.Define("token is not valid before %v")
+	ErrIssuerMismatch   = logex.Define("issuer mismatch: want %q, got %q")
+	ErrAudienceMismatch = logex.Define("audience mismatch: want %q, got %q")
+	ErrProfileMismatch  = logex.Define("unexpected eat_profile: %q")
+)
+
+var b64 = base64.RawURLEncoding
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// Issuer signs attestation result tokens as ES256 JWTs
+type Issuer struct {
+	Key *ecdsa.PrivateKey
+	// KeyID is put into the kid header, optional
+	KeyID string
+	// Name is the iss claim, optional
+	Name string
+	// TTL of the token, defaults to 1 hour
+	TTL time.Duration
+	// defaults to time.Now
+	Now func() time.Time
+}
+
+// Issue fills the registered claims which are not set and returns the signed token
+func (i *Issuer) Issue(claims *Claims) (string, error) {
+	if i.Key == nil || i.Key.Curve != elliptic.P256() {
+		return "", logex.NewErrorf("issuer requires a P-256 key")
+	}
+	now := time.Now
+	if i.Now != nil {
+		now = i.Now
+	}
+	ttl := i.TTL
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+
+	c := *claims
+	issuedAt := now()
+	if c.Issuer == "" {
+		c.Issuer = i.Name
+	}
+	if c.IssuedAt == 0 {
+		c.IssuedAt = issuedAt.Unix()
+	}
+	if c.NotBefore == 0 {
+		c.NotBefore = c.IssuedAt
+	}
+	if c.Expiry == 0 {
+		c.Expiry = issuedAt.Add(ttl).Unix()
+	}
+	if c.ID == "" {
+		var id [16]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return "", logex.Trace(err)
+		}
+		c.ID = hex.EncodeToString(id[:])
+	}
+	if c.Profile == "" {
+		c.Profile = EAT_PROFILE
+	}
+
+	headerJson, err := json.Marshal(&header{Alg: "ES256", Typ: "JWT", Kid: i.KeyID})
+	if err != nil {
+		return "", logex.Trace(err)
+	}
+	claimsJson, err := json.Marshal(&c)
+	if err != nil {
+		return "", logex.Trace(err)
+	}
+	signingInput := b64.EncodeToString(headerJson) + "." + b64.EncodeToString(claimsJson)
+	hash := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, i.Key, hash[:])
+	if err != nil {
+		return "", logex.Trace(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signingInput + "." + b64.EncodeToString(sig), nil
+}
+
+// Verifier checks attestation result tokens issued by Issuer
+type Verifier struct {
+	// Keys by kid, the only key is used if the token has no kid
+	Keys map[string]*ecdsa.PublicKey
+	// expected iss claim, not checked if empty
+	Issuer string
+	// expected aud claim, not checked if empty
+	Audience string
+	// allowed clock skew
+	Leeway time.Duration
+	// defaults to time.Now
+	Now func() time.Time
+}
+
+// Verify checks the signature and the registered claims, returns the claims of the token
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken.Trace()
+	}
+	headerJson, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformedToken.Trace(err)
+	}
+	var h header
+	if err := json.Unmarshal(headerJson, &h); err != nil {
+		return nil, ErrMalformedToken.Trace(err)
+	}
+	if h.Alg != "ES256" {
+		return nil, ErrUnsupportedAlg.Format(h.Alg)
+	}
+	key, err := v.key(h.Kid)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		return nil, ErrInvalidSignature.Trace()
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(key, hash[:], r, s) {
+		return nil, ErrInvalidSignature.Trace()
+	}
+
+	claimsJson, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken.Trace(err)
+	}
+	var claims Claims
+	if err := json.Unmarshal(claimsJson, &claims); err != nil {
+		return nil, ErrMalformedToken.Trace(err)
+	}
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &claims, nil
+}
+
+func (v *Verifier) key(kid string) (*ecdsa.PublicKey, error) {
+	if key, ok := v.Keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.Keys) == 1 {
+		for _, key := range v.Keys {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownKey.Format(kid)
+}
+
+func (v *Verifier) checkClaims(claims *Claims) error {
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	t := now()
+	if claims.Expiry != 0 {
+		expiry := time.Unix(claims.Expiry, 0)
+		if t.After(expiry.Add(v.Leeway)) {
+			return ErrTokenExpired.Format(expiry)
+		}
+	}
+	if claims.NotBefore != 0 {
+		notBefore := time.Unix(claims.NotBefore, 0)
+		if t.Before(notBefore.Add(-v.Leeway)) {
+			return ErrTokenNotYetValid.Format(notBefore)
+		}
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return ErrIssuerMismatch.Format(v.Issuer, claims.Issuer)
+	}
+	if v.Audience != "" && claims.Audience != v.Audience {
+		return ErrAudienceMismatch.Format(v.Audience, claims.Audience)
+	}
+	if claims.Profile != EAT_PROFILE {
+		return ErrProfileMismatch.Format(claims.Profile)
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package eventlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/chzyer/logex"
+)
+
+var led = binary.LittleEndian
+
+var (
+	ErrInvalidSpecIDEvent = logex.Define("invalid Spec ID event: %v")
+	ErrTruncatedEvent     = logex.Define("event log truncated at offset %v")
+	ErrUnknownAlgorithm   = logex.Define("unknown digest algorithm 0x%x at offset %v")
+)
+
+// TCG digest algorithm identifiers
+const (
+	TPM_ALG_SHA1    = uint16(0x0004)
+	TPM_ALG_SHA256  = uint16(0x000B)
+	TPM_ALG_SHA384  = uint16(0x000C)
+	TPM_ALG_SHA512  = uint16(0x000D)
+	TPM_ALG_SM3_256 = uint16(0x0012)
+)
+
+// TCG PC Client event types
+const (
+	EV_PREBOOT_CERT                  = uint32(0x00000000)
+	EV_POST_CODE                     = uint32(0x00000001)
+	EV_NO_ACTION                     = uint32(0x00000003)
+	EV_SEPARATOR                     = uint32(0x00000004)
+	EV_ACTION                        = uint32(0x00000005)
+	EV_EVENT_TAG                     = uint32(0x00000006)
+	EV_S_CRTM_CONTENTS               = uint32(0x00000007)
+	EV_S_CRTM_VERSION                = uint32(0x00000008)
+	EV_CPU_MICROCODE                 = uint32(0x00000009)
+	EV_PLATFORM_CONFIG_FLAGS         = uint32(0x0000000A)
+	EV_TABLE_OF_DEVICES              = uint32(0x0000000B)
+	EV_COMPACT_HASH                  = uint32(0x0000000C)
+	EV_IPL                           = uint32(0x0000000D)
+	EV_IPL_PARTITION_DATA            = uint32(0x0000000E)
+	EV_NONHOST_CODE                  = uint32(0x0000000F)
+	EV_NONHOST_CONFIG                = uint32(0x00000010)
+	EV_NONHOST_INFO                  = uint32(0x00000011)
+	EV_OMIT_BOOT_DEVICE_EVENTS       = uint32(0x00000012)
+	EV_EFI_VARIABLE_DRIVER_CONFIG    = uint32(0x80000001)
+	EV_EFI_VARIABLE_BOOT             = uint32(0x80000002)
+	EV_EFI_BOOT_SERVICES_APPLICATION = uint32(0x80000003)
+	EV_EFI_BOOT_SERVICES_DRIVER      = uint32(0x80000004)
+	EV_EFI_RUNTIME_SERVICES_DRIVER   = uint32(0x80000005)
+	EV_EFI_GPT_EVENT                 = uint32(0x80000006)
+	EV_EFI_ACTION                    = uint32(0x80000007)
+	EV_EFI_PLATFORM_FIRMWARE_BLOB    = uint32(0x80000008)
+	EV_EFI_HANDOFF_TABLES            = uint32(0x80000009)
+	EV_EFI_PLATFORM_FIRMWARE_BLOB2   = uint32(0x8000000A)
+	EV_EFI_HANDOFF_TABLES2           = uint32(0x8000000B)
+	EV_EFI_VARIABLE_BOOT2            = uint32(0x8000000C)
+	EV_EFI_HCRTM_EVENT               = uint32(0x80000010)
+	EV_EFI_VARIABLE_AUTHORITY        = uint32(0x800000E0)
+	EV_EFI_SPDM_FIRMWARE_BLOB        = uint32(0x800000E1)
+	EV_EFI_SPDM_FIRMWARE_CONFIG      = uint32(0x800000E2)
+)
+
+var eventTypeNames = map[uint32]string{
+	EV_PREBOOT_CERT:                  "EV_PREBOOT_CERT",
+	EV_POST_CODE:                     "EV_POST_CODE",
+	EV_NO_ACTION:                     "EV_NO_ACTION",
+	EV_SEPARATOR:                     "EV_SEPARATOR",
+	EV_ACTION:                        "EV_ACTION",
+	EV_EVENT_TAG:                     "EV_EVENT_TAG",
+	EV_S_CRTM_CONTENTS:               "EV_S_CRTM_CONTENTS",
+	EV_S_CRTM_VERSION:                "EV_S_CRTM_VERSION",
+	EV_CPU_MICROCODE:                 "EV_CPU_MICROCODE",
+	EV_PLATFORM_CONFIG_FLAGS:         "EV_PLATFORM_CONFIG_FLAGS",
+	EV_TABLE_OF_DEVICES:              "EV_TABLE_OF_DEVICES",
+	EV_COMPACT_HASH:                  "EV_COMPACT_HASH",
+	EV_IPL:                           "EV_IPL",
+	EV_IPL_PARTITION_DATA:            "EV_IPL_PARTITION_DATA",
+	EV_NONHOST_CODE:                  "EV_NONHOST_CODE",
+	EV_NONHOST_CONFIG:                "EV_NONHOST_CONFIG",
+	EV_NONHOST_INFO:                  "EV_NONHOST_INFO",
+	EV_OMIT_BOOT_DEVICE_EVENTS:       "EV_OMIT_BOOT_DEVICE_EVENTS",
+	EV_EFI_VARIABLE_DRIVER_CONFIG:    "EV_EFI_VARIABLE_DRIVER_CONFIG",
+	EV_EFI_VARIABLE_BOOT:             "EV_EFI_VARIABLE_BOOT",
+	EV_EFI_BOOT_SERVICES_APPLICATION: "EV_EFI_BOOT_SERVICES_APPLICATION",
+	EV_EFI_BOOT_SERVICES_DRIVER:      "EV_EFI_BOOT_SERVICES_DRIVER",
+	EV_EFI_RUNTIME_SERVICES_DRIVER:   "EV_EFI_RUNTIME_SERVICES_DRIVER",
+	EV_EFI_GPT_EVENT:                 "EV_EFI_GPT_EVENT",
+	EV_EFI_ACTION:                    "EV_EFI_ACTION",
+	EV_EFI_PLATFORM_FIRMWARE_BLOB:    "EV_EFI_PLATFORM_FIRMWARE_BLOB",
+	EV_EFI_HANDOFF_TABLES:            "EV_EFI_HANDOFF_TABLES",
+	EV_EFI_PLATFORM_FIRMWARE_BLOB2:   "EV_EFI_PLATFORM_FIRMWARE_BLOB2",
+	EV_EFI_HANDOFF_TABLES2:           "EV_EFI_HANDOFF_TABLES2",
+	EV_EFI_VARIABLE_BOOT2:            "EV_EFI_VARIABLE_BOOT2",
+	EV_EFI_HCRTM_EVENT:               "EV_EFI_HCRTM_EVENT",
+	EV_EFI_VARIABLE_AUTHORITY:        "EV_EFI_VARIABLE_AUTHORITY",
+	EV_EFI_SPDM_FIRMWARE_BLOB:        "EV_EFI_SPDM_FIRMWARE_BLOB",
+	EV_EFI_SPDM_FIRMWARE_CONFIG:      "EV_EFI_SPDM_FIRMWARE_CONFIG",
+}
+
+// EventTypeName returns the TCG name of the event type
+func EventTypeName(ty uint32) string {
+	if name, ok := eventTypeNames[ty]; ok {
+		return name
+	}
+	return fmt.Sprintf("EV_UNKNOWN(0x%x)", ty)
+}
+
+var specIDSignature = []byte("Spec ID Event03\x00")
+
+// AlgorithmSize describes the digest size of an algorithm used in the event log
+type AlgorithmSize struct {
+	AlgID      uint16
+	DigestSize uint16
+}
+
+// SpecIDEvent is the TCG_EfiSpecIDEvent which starts a crypto-agile event log
+type SpecIDEvent struct {
+	PlatformClass    uint32
+	SpecVersionMinor uint8
+	SpecVersionMajor uint8
+	SpecErrata       uint8
+	UintnSize        uint8
+	Algorithms       []AlgorithmSize
+	VendorInfo       []byte
+}
+
+func (s *SpecIDEvent) digestSize(alg uint16) (int, bool) {
+	for _, item := range s.Algorithms {
+		if item.AlgID == alg {
+			return int(item.DigestSize), true
+		}
+	}
+	return 0, false
+}
+
+// EventDigest is one of the digests carried by a TCG_PCR_EVENT2
+type EventDigest struct {
+	AlgID  uint16
+	Digest []byte
+}
+
+// Event is a TCG_PCR_EVENT2 entry of the CC event log.
+//
+// In the CCEL the PCRIndex field holds the CC measurement register index:
+// 0 is MRTD, 1 to 4 are RTMR0 to RTMR3.
+type Event struct {
+	Offset  int
+	MrIndex uint32
+	Type    uint32
+	Digests []EventDigest
+	Data    []byte
+}
+
+// Digest returns the digest of the given algorithm, nil if absent
+func (e *Event) Digest(alg uint16) []byte {
+	for _, d := range e.Digests {
+		if d.AlgID == alg {
+			return d.Digest
+		}
+	}
+	return nil
+}
+
+// RtmrIndex returns the RTMR the event is extended into
+func (e *Event) RtmrIndex() (int, bool) {
+	if e.MrIndex < 1 || e.MrIndex > 4 {
+		return 0, false
+	}
+	return int(e.MrIndex) - 1, true
+}
+
+func (e *Event) String() string {
+	return fmt.Sprintf("Event{offset: %v, mr_index: %v, type: %v, digest: %x, data_len: %v}", e.Offset, e.MrIndex, EventTypeName(e.Type), e.Digest(TPM_ALG_SHA384), len(e.Data))
+}
+
+// EventLog is a parsed TCG2 crypto-agile event log
+type EventLog struct {
+	SpecID *SpecIDEvent
+	Events []*Event
+}
+
+// Parse decodes the event log area dumped from the CCEL ACPI table
+// (e.g. /sys/firmware/acpi/tables/data/CCEL). Parsing stops at the first
+// unused entry of the log area.
+func Parse(data []byte) (*EventLog, error) {
+	log := new(EventLog)
+	offset, err := log.parseSpecIDEvent(data)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+
+	for offset+8 <= len(data) {
+		mrIndex := led.Uint32(data[offset:])
+		ty := led.Uint32(data[offset+4:])
+		if ty == 0xFFFFFFFF || (mrIndex == 0 && ty == 0) {
+			break
+		}
+		event, next, err := log.parseEvent(data, offset)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		log.Events = append(log.Events, event)
+		offset = next
+	}
+	return log, nil
+}
+
+// parseSpecIDEvent decodes the leading TCG_PCClientPCREvent, which always uses the SHA1 log format
+func (l *EventLog) parseSpecIDEvent(data []byte) (int, error) {
+	// pcrIndex(4) + eventType(4) + sha1(20) + eventSize(4)
+	const headerSize = 32
+	if len(data) < headerSize {
+		return 0, ErrTruncatedEvent.Format(0)
+	}
+	if ty := led.Uint32(data[4:]); ty != EV_NO_ACTION {
+		return 0, ErrInvalidSpecIDEvent.Format(fmt.Sprintf("event type %v", EventTypeName(ty)))
+	}
+	eventSize := int(led.Uint32(data[28:]))
+	if len(data) < headerSize+eventSize {
+		return 0, ErrTruncatedEvent.Format(0)
+	}
+	event := data[headerSize : headerSize+eventSize]
+	// signature(16) + platformClass(4) + versions(4) + numberOfAlgorithms(4)
+	if len(event) < 28 || !bytes.Equal(event[:16], specIDSignature) {
+		return 0, ErrInvalidSpecIDEvent.Format("bad signature")
+	}
+
+	spec := &SpecIDEvent{
+		PlatformClass:    led.Uint32(event[16:]),
+		SpecVersionMinor: event[20],
+		SpecVersionMajor: event[21],
+		SpecErrata:       event[22],
+		UintnSize:        event[23],
+	}
+	numAlgs := int(led.Uint32(event[24:]))
+	off := 28
+	if len(event) < off+numAlgs*4+1 {
+		return 0, ErrInvalidSpecIDEvent.Format("truncated algorithms")
+	}
+	for i := 0; i < numAlgs; i++ {
+		spec.Algorithms = append(spec.Algorithms, AlgorithmSize{
+			AlgID:      led.Uint16(event[off:]),
+			DigestSize: led.Uint16(event[off+2:]),
+		})
+		off += 4
+	}
+	vendorSize := int(event[off])
+	off++
+	if len(event) < off+vendorSize {
+		return 0, ErrInvalidSpecIDEvent.Format("truncated vendor info")
+	}
+	spec.VendorInfo = event[off : off+vendorSize]
+
+	l.SpecID = spec
+	return headerSize + eventSize, nil
+}
+
+// parseEvent decodes a TCG_PCR_EVENT2 starting at offset and returns the offset of the next one
+func (l *EventLog) parseEvent(data []byte, offset int) (*Event, int, error) {
+	off := offset
+	if len(data) < off+12 {
+		return nil, 0, ErrTruncatedEvent.Format(offset)
+	}
+	event := &Event{
+		Offset:  offset,
+		MrIndex: led.Uint32(data[off:]),
+		Type:    led.Uint32(data[off+4:]),
+	}
+	count := int(led.Uint32(data[off+8:]))
+	off += 12
+	for i := 0; i < count; i++ {
+		if len(data) < off+2 {
+			return nil, 0, ErrTruncatedEvent.Format(offset)
+		}
+		alg := led.Uint16(data[off:])
+		size, ok := l.SpecID.digestSize(alg)
+		if !ok {
+			return nil, 0, ErrUnknownAlgorithm.Format(alg, offset)
+		}
+		off += 2
+		if len(data) < off+size {
+			return nil, 0, ErrTruncatedEvent.Format(offset)
+		}
+		event.Digests = append(event.Digests, EventDigest{AlgID: alg, Digest: data[off : off+size]})
+		off += size
+	}
+	if len(data) < off+4 {
+		return nil, 0, ErrTruncatedEvent.Format(offset)
+	}
+	eventSize := int(led.Uint32(data[off:]))
+	off += 4
+	if len(data) < off+eventSize {
+		return nil, 0, ErrTruncatedEvent.Format(offset)
+	}
+	event.Data = data[off : off+eventSize]
+	off += eventSize
+	return event, off, nil
+}
+
+// CCEL is the Confidential Computing Event Log ACPI table
+type CCEL struct {
+	CcType    uint8
+	CcSubType uint8
+	// Log Area Minimum Length
+	Laml uint64
+	// Log Area Start Address
+	Lasa uint64
+}
+
+// ParseCCEL decodes the CCEL ACPI table (e.g. /sys/firmware/acpi/tables/CCEL),
+// which locates the event log area in memory
+func ParseCCEL(table []byte) (*CCEL, error) {
+	// acpi header(36) + ccType(1) + ccSubType(1) + reserved(2) + laml(8) + lasa(8)
+	if len(table) < 56 {
+		return nil, logex.NewErrorf("CCEL table too short: %v", len(table))
+	}
+	if string(table[:4]) != "CCEL" {
+		return nil, logex.NewErrorf("unexpected ACPI table signature: %q", table[:4])
+	}
+	return &CCEL{
+		CcType:    table[36],
+		CcSubType: table[37],
+		Laml:      led.Uint64(table[40:]),
+		Lasa:      led.Uint64(table[48:]),
+	}, nil
+}
@@ -0,0 +1,95 @@
+package eventlog
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/chzyer/test"
+)
+
+//go:embed test_ccel.hex
+var testCcel string
+
+var testRtmrs = [RTMR_COUNT]string{
+	"2e8bfdbf54f91bcbffaeaa3ca85bfe39bc11979b72a6c685b4e49b88db6b7b8afbe50ae077de6f087ee5066d56ffad29",
+	"19e88c59f7ad9e05be0f09cf3e9f8115098c3b19fcf337254cd2a48a1db0995cc0d0429184f0785201584ea54fa74922",
+	"58af528c0cc6723a59bb4a99ab915bf834206256c6611423610d3b434be1425b11272f9e58a38304e464bfe44cc3b29a",
+	"000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+}
+
+// RTMR1 after extending only the first event
+const testRtmr1Prefix = "be6fdd83f02f9548529e53906d73bcdd9bd911b16257417677dfa8ecbb437e39b9e472cb5061cee793b90beb61bed243"
+
+func testEventLog() *EventLog {
+	data, err := hex.DecodeString(testCcel)
+	test.Nil(err)
+	log, err := Parse(data)
+	test.Nil(err)
+	return log
+}
+
+// testQuote returns the mock TDX quote with its RTMRs replaced
+func testQuote(rtmrs [RTMR_COUNT]string) []byte {
+	quote := append([]byte(nil), mock.Quotes[1]...)
+	// header(48) + tee_tcb_svn .. mr_owner_config(328)
+	offset := 48 + 328
+	for i, rtmr := range rtmrs {
+		value, err := hex.DecodeString(rtmr)
+		test.Nil(err)
+		copy(quote[offset+48*i:], value)
+	}
+	return quote
+}
+
+func TestParse(t *testing.T) {
+	defer test.New(t)
+	log := testEventLog()
+
+	test.Equal(len(log.SpecID.Algorithms), 1)
+	test.Equal(log.SpecID.Algorithms[0].AlgID, TPM_ALG_SHA384)
+	test.Equal(len(log.Events), 9)
+	test.Equal(log.Events[0].Type, EV_EFI_PLATFORM_FIRMWARE_BLOB)
+	test.Equal(string(log.Events[8].Data), "initrd /boot/initrd.img")
+	test.Equal(len(log.RtmrEvents(0)), 4)
+	test.Equal(len(log.RtmrEvents(3)), 0)
+}
+
+func TestReplay(t *testing.T) {
+	defer test.New(t)
+	log := testEventLog()
+
+	rtmrs, err := log.Replay()
+	test.Nil(err)
+	for i := range rtmrs {
+		test.Equal(rtmrs[i].String(), testRtmrs[i])
+	}
+}
+
+func TestCompare(t *testing.T) {
+	defer test.New(t)
+	log := testEventLog()
+
+	divergences, err := log.CompareQuote(testQuote(testRtmrs))
+	test.Nil(err)
+	test.Equal(len(divergences), 0)
+
+	// the quote only covers the first event of RTMR1
+	rtmrs := testRtmrs
+	rtmrs[1] = testRtmr1Prefix
+	divergences, err = log.CompareQuote(testQuote(rtmrs))
+	test.Nil(err)
+	test.Equal(len(divergences), 1)
+	test.Equal(divergences[0].Rtmr, 1)
+	test.Equal(len(divergences[0].Events), 1)
+	test.Equal(string(divergences[0].Events[0].Data), "vmlinuz-6.8.0-tdx")
+
+	// the original mock quote was not produced by this event log
+	divergences, err = log.CompareQuote(mock.Quotes[1])
+	test.Nil(err)
+	test.True(len(divergences) > 0)
+
+	_, err = log.CompareQuote(mock.Quotes[0])
+	test.NotNil(err)
+}
@@ -0,0 +1,123 @@
+package eventlog
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"fmt"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/chzyer/logex"
+)
+
+// RTMR_COUNT is the number of runtime measurement registers of a TD
+const RTMR_COUNT = 4
+
+var ErrMissingSha384 = logex.Define("event at offset %v has no SHA-384 digest")
+
+// Rtmr is the value of a runtime measurement register
+type Rtmr [48]byte
+
+// Extend extends the register with the digest: RTMR = SHA384(RTMR || digest)
+func (r Rtmr) Extend(digest []byte) Rtmr {
+	h := sha512.New384()
+	h.Write(r[:])
+	h.Write(digest)
+	var out Rtmr
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+func (r Rtmr) String() string {
+	return fmt.Sprintf("%x", r[:])
+}
+
+// RtmrEvents returns the events extended into the RTMR
+func (l *EventLog) RtmrEvents(idx int) []*Event {
+	var events []*Event
+	for _, event := range l.Events {
+		if i, ok := event.RtmrIndex(); ok && i == idx && event.Type != EV_NO_ACTION {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Replay replays the SHA-384 extends recorded in the event log and returns the expected RTMRs
+func (l *EventLog) Replay() ([RTMR_COUNT]Rtmr, error) {
+	var rtmrs [RTMR_COUNT]Rtmr
+	for i := range rtmrs {
+		for _, event := range l.RtmrEvents(i) {
+			digest := event.Digest(TPM_ALG_SHA384)
+			if digest == nil {
+				return rtmrs, ErrMissingSha384.Format(event.Offset)
+			}
+			rtmrs[i] = rtmrs[i].Extend(digest)
+		}
+	}
+	return rtmrs, nil
+}
+
+// Divergence describes an RTMR whose replayed value differs from the quote
+type Divergence struct {
+	Rtmr     int
+	Replayed Rtmr
+	Reported Rtmr
+	// Events are the events which cannot be reconciled with the reported value.
+	// If a prefix of the log replays to the reported value, these are the
+	// events after that prefix. Otherwise they are all events of the RTMR.
+	Events []*Event
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf("RTMR%v mismatch: replayed=%v, reported=%v, diverging events=%v", d.Rtmr, d.Replayed, d.Reported, len(d.Events))
+}
+
+// Compare replays the event log and compares it with the RTMRs of the TD report.
+// An empty result means the event log matches the quote.
+func (l *EventLog) Compare(report *parser.TdReport10) ([]*Divergence, error) {
+	var divergences []*Divergence
+	for i := 0; i < RTMR_COUNT; i++ {
+		reported := Rtmr(report.Rtmr[i])
+		events := l.RtmrEvents(i)
+
+		var current Rtmr
+		matched := -1
+		if bytes.Equal(current[:], reported[:]) {
+			matched = 0
+		}
+		for idx, event := range events {
+			digest := event.Digest(TPM_ALG_SHA384)
+			if digest == nil {
+				return nil, ErrMissingSha384.Format(event.Offset)
+			}
+			current = current.Extend(digest)
+			if bytes.Equal(current[:], reported[:]) {
+				matched = idx + 1
+			}
+		}
+		if matched == len(events) {
+			continue
+		}
+
+		divergence := &Divergence{
+			Rtmr:     i,
+			Replayed: current,
+			Reported: reported,
+			Events:   events,
+		}
+		if matched >= 0 {
+			divergence.Events = events[matched:]
+		}
+		divergences = append(divergences, divergence)
+	}
+	return divergences, nil
+}
+
+// CompareQuote compares the event log with the RTMRs of a V4 TDX quote
+func (l *EventLog) CompareQuote(quote []byte) ([]*Divergence, error) {
+	report, err := parser.NewQuoteParser(quote).TdReport()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return l.Compare(report)
+}
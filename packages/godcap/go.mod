@@ -6,6 +6,7 @@ require (
 	github.com/chzyer/flagly v1.0.0
 	github.com/chzyer/logex v1.2.1
 	github.com/chzyer/test v1.0.0
+	github.com/consensys/gnark-crypto v0.12.1
 	github.com/ethereum/go-ethereum v1.14.12
 	google.golang.org/grpc v1.70.0
 	google.golang.org/protobuf v1.35.2
@@ -16,7 +17,6 @@ require (
 	github.com/StackExchange/wmi v1.2.1 // indirect
 	github.com/bits-and-blooms/bitset v1.13.0 // indirect
 	github.com/consensys/bavard v0.1.13 // indirect
-	github.com/consensys/gnark-crypto v0.12.1 // indirect
 	github.com/crate-crypto/go-ipa v0.0.0-20240223125850-b1e8a79f509c // indirect
 	github.com/crate-crypto/go-kzg-4844 v1.0.0 // indirect
 	github.com/deckarep/golang-set/v2 v2.6.0 // indirect
@@ -0,0 +1,202 @@
+// Package groth16 verifies Groth16 proofs over bn254 the same way as the on-chain verifiers
+package groth16
+
+import (
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/chzyer/logex"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/ethereum/go-ethereum/crypto/bn256"
+)
+
+var (
+	ErrInvalidProof        = logex.Define("invalid groth16 proof")
+	ErrVerification        = logex.Define("groth16 verification failed")
+	ErrInvalidVerifyingKey = logex.Define("invalid groth16 verifying key")
+)
+
+// PROOF_SIZE is the size of a, b and c in the encoding of the bn254 precompiles
+const PROOF_SIZE = 256
+
+// SCALAR_FIELD is the order of the groups, public inputs must be smaller than it
+var SCALAR_FIELD, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+type VerifyingKey struct {
+	Alpha *bn256.G1
+	Beta  *bn256.G2
+	Gamma *bn256.G2
+	Delta *bn256.G2
+	// IC has one more point than the public inputs
+	IC []*bn256.G1
+}
+
+// Verify checks the proof of the public inputs.
+// The proof is a(64) | b(128) | c(64), the coordinates of b are ordered as x.imag, x.real, y.imag, y.real
+func (vk *VerifyingKey) Verify(proof []byte, inputs []*big.Int) error {
+	if len(proof) != PROOF_SIZE {
+		return ErrInvalidProof.Trace(len(proof))
+	}
+	if len(inputs)+1 != len(vk.IC) {
+		return ErrInvalidProof.Trace("public inputs", len(inputs))
+	}
+	a := new(bn256.G1)
+	if _, err := a.Unmarshal(proof[0:64]); err != nil {
+		return ErrInvalidProof.Trace(err)
+	}
+	b := new(bn256.G2)
+	if _, err := b.Unmarshal(proof[64:192]); err != nil {
+		return ErrInvalidProof.Trace(err)
+	}
+	c := new(bn256.G1)
+	if _, err := c.Unmarshal(proof[192:256]); err != nil {
+		return ErrInvalidProof.Trace(err)
+	}
+
+	vkX := new(bn256.G1).Set(vk.IC[0])
+	for i, input := range inputs {
+		if input.Sign() < 0 || input.Cmp(SCALAR_FIELD) >= 0 {
+			return ErrInvalidProof.Trace("public input out of range")
+		}
+		vkX.Add(vkX, new(bn256.G1).ScalarMult(vk.IC[i+1], input))
+	}
+
+	// e(-a, b) * e(alpha, beta) * e(vkX, gamma) * e(c, delta) == 1
+	ok := bn256.PairingCheck(
+		[]*bn256.G1{new(bn256.G1).Neg(a), vk.Alpha, vkX, c},
+		[]*bn256.G2{b, vk.Beta, vk.Gamma, vk.Delta},
+	)
+	if !ok {
+		return ErrVerification.Trace()
+	}
+	return nil
+}
+
+// MustG1 creates the point from the decimal coordinates, e.g. the constants of a solidity verifier
+func MustG1(x, y string) *bn256.G1 {
+	data := make([]byte, 64)
+	mustBigInt(x).FillBytes(data[:32])
+	mustBigInt(y).FillBytes(data[32:])
+	p := new(bn256.G1)
+	if _, err := p.Unmarshal(data); err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// MustG2 takes the coordinates in the order of the bn254 precompile: x.imag, x.real, y.imag, y.real
+func MustG2(xi, xr, yi, yr string) *bn256.G2 {
+	data := make([]byte, 128)
+	for i, s := range []string{xi, xr, yi, yr} {
+		mustBigInt(s).FillBytes(data[32*i : 32*(i+1)])
+	}
+	p := new(bn256.G2)
+	if _, err := p.Unmarshal(data); err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func mustBigInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid integer: " + s)
+	}
+	return n
+}
+
+// ParseGnarkVerifyingKey decodes the verifying key serialized by gnark with compressed points,
+// e.g. groth16_vk.bin of the SP1 circuits. Keys with pedersen commitments are not supported.
+//
+// Layout: alpha(g1) | beta(g1) | beta(g2) | gamma(g2) | delta(g1) | delta(g2) | len(K)(u32) | K(g1)... |
+// len(PublicAndCommitmentCommitted)(u32) | ...
+func ParseGnarkVerifyingKey(data []byte) (*VerifyingKey, error) {
+	r := &gnarkReader{data: data}
+	vk := new(VerifyingKey)
+	vk.Alpha = r.g1()
+	r.g1() // beta in g1 is only used by the prover
+	vk.Beta = r.g2()
+	vk.Gamma = r.g2()
+	r.g1() // delta in g1 is only used by the prover
+	vk.Delta = r.g2()
+	size := r.u32()
+	if r.err == nil && size > uint32(len(data)/bn254.SizeOfG1AffineCompressed) {
+		return nil, ErrInvalidVerifyingKey.Trace("size of K", size)
+	}
+	for i := uint32(0); i < size; i++ {
+		vk.IC = append(vk.IC, r.g1())
+	}
+	commitments := r.u32()
+	if r.err != nil {
+		return nil, ErrInvalidVerifyingKey.Trace(r.err)
+	}
+	if commitments != 0 {
+		return nil, ErrInvalidVerifyingKey.Trace("commitments are not supported")
+	}
+	return vk, nil
+}
+
+type gnarkReader struct {
+	data []byte
+	err  error
+}
+
+func (r *gnarkReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out
+}
+
+func (r *gnarkReader) u32() uint32 {
+	data := r.next(4)
+	if data == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(data)
+}
+
+func (r *gnarkReader) g1() *bn256.G1 {
+	data := r.next(bn254.SizeOfG1AffineCompressed)
+	if data == nil {
+		return nil
+	}
+	var p bn254.G1Affine
+	if _, err := p.SetBytes(data); err != nil {
+		r.err = err
+		return nil
+	}
+	raw := p.RawBytes()
+	out := new(bn256.G1)
+	if _, err := out.Unmarshal(raw[:]); err != nil {
+		r.err = err
+		return nil
+	}
+	return out
+}
+
+func (r *gnarkReader) g2() *bn256.G2 {
+	data := r.next(bn254.SizeOfG2AffineCompressed)
+	if data == nil {
+		return nil
+	}
+	var p bn254.G2Affine
+	if _, err := p.SetBytes(data); err != nil {
+		r.err = err
+		return nil
+	}
+	raw := p.RawBytes()
+	out := new(bn256.G2)
+	if _, err := out.Unmarshal(raw[:]); err != nil {
+		r.err = err
+		return nil
+	}
+	return out
+}
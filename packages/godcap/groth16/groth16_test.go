@@ -0,0 +1,84 @@
+package groth16_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/groth16"
+	"github.com/automata-network/dcap-sdk/packages/godcap/testutil"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/ethereum/go-ethereum/crypto/bn256"
+)
+
+func TestVerify(t *testing.T) {
+	defer test.New(t)
+	vk, td, err := testutil.NewGroth16TestKey(2)
+	test.Nil(err)
+	inputs := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	proof, err := td.Prove(inputs)
+	test.Nil(err)
+	test.Nil(vk.Verify(proof, inputs))
+
+	test.True(logex.Equal(vk.Verify(proof, []*big.Int{big.NewInt(1), big.NewInt(3)}), groth16.ErrVerification))
+	test.True(logex.Equal(vk.Verify(proof, inputs[:1]), groth16.ErrInvalidProof))
+	test.True(logex.Equal(vk.Verify(proof[:200], inputs), groth16.ErrInvalidProof))
+	test.True(logex.Equal(vk.Verify(proof, []*big.Int{groth16.SCALAR_FIELD, big.NewInt(2)}), groth16.ErrInvalidProof))
+	proof[63] ^= 1
+	test.True(logex.Equal(vk.Verify(proof, inputs), groth16.ErrInvalidProof))
+}
+
+func gnarkG1(p *bn256.G1) []byte {
+	var out bn254.G1Affine
+	_, err := out.SetBytes(p.Marshal())
+	test.Nil(err)
+	compressed := out.Bytes()
+	return compressed[:]
+}
+
+func gnarkG2(p *bn256.G2) []byte {
+	var out bn254.G2Affine
+	_, err := out.SetBytes(p.Marshal())
+	test.Nil(err)
+	compressed := out.Bytes()
+	return compressed[:]
+}
+
+func TestParseGnarkVerifyingKey(t *testing.T) {
+	defer test.New(t)
+	vk, td, err := testutil.NewGroth16TestKey(2)
+	test.Nil(err)
+
+	var buf bytes.Buffer
+	buf.Write(gnarkG1(vk.Alpha))
+	buf.Write(gnarkG1(vk.Alpha))
+	buf.Write(gnarkG2(vk.Beta))
+	buf.Write(gnarkG2(vk.Gamma))
+	buf.Write(gnarkG1(vk.Alpha))
+	buf.Write(gnarkG2(vk.Delta))
+	binary.Write(&buf, binary.BigEndian, uint32(len(vk.IC)))
+	for _, p := range vk.IC {
+		buf.Write(gnarkG1(p))
+	}
+	binary.Write(&buf, binary.BigEndian, uint32(0))
+
+	parsed, err := groth16.ParseGnarkVerifyingKey(buf.Bytes())
+	test.Nil(err)
+	test.Equal(parsed.Alpha.Marshal(), vk.Alpha.Marshal())
+	test.Equal(parsed.Delta.Marshal(), vk.Delta.Marshal())
+	test.Equal(len(parsed.IC), len(vk.IC))
+
+	inputs := []*big.Int{big.NewInt(5), big.NewInt(6)}
+	proof, err := td.Prove(inputs)
+	test.Nil(err)
+	test.Nil(parsed.Verify(proof, inputs))
+
+	_, err = groth16.ParseGnarkVerifyingKey(buf.Bytes()[:300])
+	test.True(logex.Equal(err, groth16.ErrInvalidVerifyingKey))
+	withCommitment := append(buf.Bytes()[:buf.Len()-4:buf.Len()-4], 0, 0, 0, 1)
+	_, err = groth16.ParseGnarkVerifyingKey(withCommitment)
+	test.True(logex.Equal(err, groth16.ErrInvalidVerifyingKey))
+}
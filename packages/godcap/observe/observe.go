@@ -0,0 +1,152 @@
+// Package observe reports the progress of the proofs generated by the zk backends
+package observe
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chzyer/logex"
+)
+
+// EventKind is the stage of the proof lifecycle
+type EventKind uint8
+
+const (
+	// EventInputUploaded is emitted once the guest input is stored by the backend
+	EventInputUploaded EventKind = iota + 1
+	// EventSessionCreated is emitted once the proving session or request is created
+	EventSessionCreated
+	// EventStateChanged is emitted when the backend reports a new State
+	EventStateChanged
+	// EventCycles reports the cycles of the execution
+	EventCycles
+	// EventSnarkStarted is emitted once the stark receipt is being compressed into a snark
+	EventSnarkStarted
+	// EventFulfilled is emitted once the proof is downloaded
+	EventFulfilled
+	// EventFailed is emitted if the backend gives up the proof, Err is the reason
+	EventFailed
+)
+
+var eventKindNames = map[EventKind]string{
+	EventInputUploaded:  "input_uploaded",
+	EventSessionCreated: "session_created",
+	EventStateChanged:   "state_changed",
+	EventCycles:         "cycles",
+	EventSnarkStarted:   "snark_started",
+	EventFulfilled:      "fulfilled",
+	EventFailed:         "failed",
+}
+
+func (k EventKind) String() string {
+	if name, ok := eventKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("EventKind(%d)", k)
+}
+
+func (k EventKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+const (
+	SourceBonsai = "bonsai"
+	SourceSp1    = "sp1"
+	SourceZkDcap = "zkdcap"
+)
+
+// Event is emitted by the clients during the lifecycle of a proof
+type Event struct {
+	Kind EventKind `json:"kind"`
+	// Source is the emitter, one of SourceBonsai, SourceSp1 and SourceZkDcap
+	Source string `json:"source"`
+	// ID of the input, session, snark session or request, depending on Kind.
+	// The events of zkdcap use the job id, or the hex of the input digest without a job.
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+	// ZkType of the prover, only set by zkdcap
+	ZkType uint8 `json:"zk_type,omitempty"`
+
+	// State reported by the backend, e.g. "ProveSegments: 1/2" or "Assigned/Executed"
+	State string `json:"state,omitempty"`
+	// Cycles, TotalCycles and Segments of EventCycles
+	Cycles      uint64 `json:"cycles,omitempty"`
+	TotalCycles uint64 `json:"total_cycles,omitempty"`
+	Segments    int    `json:"segments,omitempty"`
+	// Err of EventFailed
+	Err error `json:"-"`
+}
+
+func (e *Event) String() string {
+	fields := []string{fmt.Sprintf("%v %v %v", e.Source, e.Kind, e.ID)}
+	if e.State != "" {
+		fields = append(fields, "state="+e.State)
+	}
+	if e.Kind == EventCycles {
+		fields = append(fields, fmt.Sprintf("cycles=%v total_cycles=%v segments=%v", e.Cycles, e.TotalCycles, e.Segments))
+	}
+	if e.Err != nil {
+		fields = append(fields, fmt.Sprintf("err=%v", e.Err))
+	}
+	return strings.Join(fields, " ")
+}
+
+// Observer receives the events, Observe is called synchronously by the clients and shouldn't block
+type Observer interface {
+	Observe(ev *Event)
+}
+
+// ObserverFunc adapts a function to an Observer
+type ObserverFunc func(ev *Event)
+
+func (f ObserverFunc) Observe(ev *Event) {
+	f(ev)
+}
+
+// Observers fans out the events
+type Observers []Observer
+
+func (o Observers) Observe(ev *Event) {
+	for _, observer := range o {
+		observer.Observe(ev)
+	}
+}
+
+// LogObserver logs the events, it's used by the clients if no observer is configured
+type LogObserver struct{}
+
+func (LogObserver) Observe(ev *Event) {
+	if ev.Kind == EventFailed {
+		logex.Error(ev)
+		return
+	}
+	logex.Info(ev)
+}
+
+// Emitter fills in the source and the time of the events sent to an observer
+type Emitter struct {
+	Source   string
+	Observer Observer
+	Now      func() time.Time
+}
+
+// NewEmitter creates an emitter of the source, the observer defaults to LogObserver
+func NewEmitter(source string, observer Observer) *Emitter {
+	if observer == nil {
+		observer = LogObserver{}
+	}
+	return &Emitter{Source: source, Observer: observer, Now: time.Now}
+}
+
+// Emit sends the event, it's a no-op for a nil emitter
+func (e *Emitter) Emit(ev *Event) {
+	if e == nil {
+		return
+	}
+	ev.Source = e.Source
+	if ev.Time.IsZero() {
+		ev.Time = e.Now()
+	}
+	e.Observer.Observe(ev)
+}
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// TcbStatus is the TCB status reported by the DCAP quote verification
+type TcbStatus uint8
+
+const (
+	TcbOK TcbStatus = iota
+	TcbSwHardeningNeeded
+	TcbConfigurationAndSwHardeningNeeded
+	TcbConfigurationNeeded
+	TcbOutOfDate
+	TcbOutOfDateConfigurationNeeded
+	TcbRevoked
+	TcbUnrecognized
+)
+
+var tcbStatusNames = []string{
+	"OK",
+	"SWHardeningNeeded",
+	"ConfigurationAndSWHardeningNeeded",
+	"ConfigurationNeeded",
+	"OutOfDate",
+	"OutOfDateConfigurationNeeded",
+	"Revoked",
+	"Unrecognized",
+}
+
+func (s TcbStatus) String() string {
+	if int(s) < len(tcbStatusNames) {
+		return tcbStatusNames[s]
+	}
+	return fmt.Sprintf("TcbStatus(%v)", uint8(s))
+}
+
+var advisoryIDsArgs = func() abi.Arguments {
+	ty, err := abi.NewType("string[]", "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return abi.Arguments{{Type: ty}}
+}()
+
+// VerifiedOutput is the output of a successful quote verification,
+// see dcap-portal/src/lib/Output.sol
+type VerifiedOutput struct {
+	QuoteVersion uint16
+	TeeType      uint32
+	TcbStatus    TcbStatus
+	Fmspc        [6]byte
+	QuoteBody    []byte
+	AdvisoryIDs  []string
+}
+
+// ParseVerifiedOutput decodes the serialized output returned by the attestation contract
+func ParseVerifiedOutput(data []byte) (*VerifiedOutput, error) {
+	// quoteVersion(2) + tee(4) + tcbStatus(1) + fmspc(6)
+	const headerSize = 13
+	if len(data) < headerSize {
+		return nil, logex.NewErrorf("verified output too short: %v", len(data))
+	}
+	be := binary.BigEndian
+	output := &VerifiedOutput{
+		QuoteVersion: be.Uint16(data[0:2]),
+		// the tee type is copied from the quote header, which is little endian
+		TeeType:   binary.LittleEndian.Uint32(data[2:6]),
+		TcbStatus: TcbStatus(data[6]),
+	}
+	copy(output.Fmspc[:], data[7:13])
+
+	bodySize := ENCLAVE_REPORT_SIZE
+	if output.TeeType == TDX_TEE_TYPE {
+		bodySize = TD_REPORT10_SIZE
+	}
+	if len(data) < headerSize+bodySize {
+		return nil, logex.NewErrorf("verified output too short for the quote body: %v", len(data))
+	}
+	output.QuoteBody = data[headerSize : headerSize+bodySize]
+
+	if rest := data[headerSize+bodySize:]; len(rest) > 0 {
+		values, err := advisoryIDsArgs.Unpack(rest)
+		if err != nil {
+			return nil, logex.Trace(err, "advisoryIDs")
+		}
+		output.AdvisoryIDs = values[0].([]string)
+	}
+	return output, nil
+}
+
+// IsTdx reports whether the output is from a TDX quote
+func (o *VerifiedOutput) IsTdx() bool {
+	return o.TeeType == TDX_TEE_TYPE
+}
+
+// QuoteSpec returns the spec of the verified quote, e.g. to look up its collateral
+func (o *VerifiedOutput) QuoteSpec() (QuoteSpec, error) {
+	switch {
+	case o.QuoteVersion == V3_QUOTE:
+		return &V3QuoteSpec{}, nil
+	case o.QuoteVersion == V4_QUOTE && (o.TeeType == SGX_TEE_TYPE || o.TeeType == TDX_TEE_TYPE):
+		return &V4QuoteSpec{TeeType: o.TeeType}, nil
+	}
+	return nil, logex.NewErrorf("unsupported quote: version %v, tee type %#x", o.QuoteVersion, o.TeeType)
+}
+
+// FmspcHex returns the FMSPC in the hex form used by PCCS
+func (o *VerifiedOutput) FmspcHex() string {
+	return hex.EncodeToString(o.Fmspc[:])
+}
+
+// EnclaveReport parses the quote body of a SGX output
+func (o *VerifiedOutput) EnclaveReport() (*EnclaveReport, error) {
+	if o.IsTdx() {
+		return nil, ErrNotSgxQuote.Trace()
+	}
+	return NewEnclaveReport(o.QuoteBody)
+}
+
+// TdReport parses the quote body of a TDX output
+func (o *VerifiedOutput) TdReport() (*TdReport10, error) {
+	if !o.IsTdx() {
+		return nil, ErrNotTdxQuote.Trace()
+	}
+	return NewTdReport10(o.QuoteBody)
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/chzyer/logex"
+)
+
+const QUOTE_HEADER_SIZE = 48
+const TD_REPORT10_SIZE = 584
+const ENCLAVE_REPORT_SIZE = 384
+
+var ErrNotTdxQuote = logex.Define("not a TDX quote")
+var ErrNotSgxQuote = logex.Define("not a SGX quote")
+
+// EnclaveReport is the SGX enclave report body carried by a V3 or V4 SGX quote
+type EnclaveReport struct {
+	CpuSvn     [16]byte
+	MiscSelect uint32
+	Reserved1  [28]byte
+	Attributes [16]byte
+	MrEnclave  [32]byte
+	Reserved2  [32]byte
+	MrSigner   [32]byte
+	Reserved3  [96]byte
+	IsvProdId  uint16
+	IsvSvn     uint16
+	Reserved4  [60]byte
+	ReportData [64]byte
+}
+
+func NewEnclaveReport(data []byte) (*EnclaveReport, error) {
+	if len(data) < ENCLAVE_REPORT_SIZE {
+		return nil, logex.NewErrorf("enclave report too short: %v", len(data))
+	}
+	var report EnclaveReport
+	if err := binary.Read(bytes.NewReader(data[:ENCLAVE_REPORT_SIZE]), led, &report); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &report, nil
+}
+
+// TdReport10 is the TD report body (TDX 1.0) carried by a V4 TDX quote
+type TdReport10 struct {
+	TeeTcbSvn      [16]byte
+	MrSeam         [48]byte
+	MrSignerSeam   [48]byte
+	SeamAttributes [8]byte
+	TdAttributes   [8]byte
+	Xfam           [8]byte
+	MrTd           [48]byte
+	MrConfigId     [48]byte
+	MrOwner        [48]byte
+	MrOwnerConfig  [48]byte
+	Rtmr           [4][48]byte
+	ReportData     [64]byte
+}
+
+func NewTdReport10(data []byte) (*TdReport10, error) {
+	if len(data) < TD_REPORT10_SIZE {
+		return nil, logex.NewErrorf("td report too short: %v", len(data))
+	}
+	var report TdReport10
+	if err := binary.Read(bytes.NewReader(data[:TD_REPORT10_SIZE]), led, &report); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &report, nil
+}
+
+// IsTdx reports whether the quote is a V4 TDX quote
+func (q *QuoteParser) IsTdx() bool {
+	spec, ok := q.spec.(*V4QuoteSpec)
+	return ok && spec.TeeType == TDX_TEE_TYPE
+}
+
+// IsSgx reports whether the quote is a V3 or V4 SGX quote
+func (q *QuoteParser) IsSgx() bool {
+	switch spec := q.spec.(type) {
+	case *V3QuoteSpec:
+		return true
+	case *V4QuoteSpec:
+		return spec.TeeType == SGX_TEE_TYPE
+	default:
+		return false
+	}
+}
+
+// EnclaveReport parses the enclave report from the quote body
+func (q *QuoteParser) EnclaveReport() (*EnclaveReport, error) {
+	if !q.IsSgx() {
+		return nil, ErrNotSgxQuote.Trace()
+	}
+	if len(q.quote) < QUOTE_HEADER_SIZE {
+		return nil, logex.NewErrorf("quote too short: %v", len(q.quote))
+	}
+	return NewEnclaveReport(q.quote[QUOTE_HEADER_SIZE:])
+}
+
+// TdReport parses the TD report from the quote body
+func (q *QuoteParser) TdReport() (*TdReport10, error) {
+	if !q.IsTdx() {
+		return nil, ErrNotTdxQuote.Trace()
+	}
+	if len(q.quote) < QUOTE_HEADER_SIZE {
+		return nil, logex.NewErrorf("quote too short: %v", len(q.quote))
+	}
+	return NewTdReport10(q.quote[QUOTE_HEADER_SIZE:])
+}
+
+// ReportData returns the report data of the SGX enclave report or TDX TD report
+func (q *QuoteParser) ReportData() ([64]byte, error) {
+	if q.IsTdx() {
+		report, err := q.TdReport()
+		if err != nil {
+			return [64]byte{}, logex.Trace(err)
+		}
+		return report.ReportData, nil
+	}
+	report, err := q.EnclaveReport()
+	if err != nil {
+		return [64]byte{}, logex.Trace(err)
+	}
+	return report.ReportData, nil
+}
@@ -70,6 +70,15 @@ func NewClient(client *ethclient.Client, chain *ChainConfig) (*Client, error) {
 	}, nil
 }
 
+// BlockNumber returns the latest block number of the chain
+func (p *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	number, err := p.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, logex.Trace(err)
+	}
+	return number, nil
+}
+
 // CertCrl holds certificate and CRL data
 type CertCrl struct {
 	Cert []byte
@@ -136,3 +145,45 @@ func (p *Client) GetEnclaveID(ctx context.Context, enclaveId uint8, version uint
 	info.Signature = hex.EncodeToString(result.Signature)
 	return &info, nil
 }
+
+// PcsCollateralHash returns the hash of the certificate or the CRL of the CA stored on chain
+func (p *Client) PcsCollateralHash(ctx context.Context, ca uint8, isCrl bool) (common.Hash, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	key, err := p.pcs.PCSKEY(opts, ca, isCrl)
+	if err != nil {
+		return common.Hash{}, logex.Trace(err)
+	}
+	hash, err := p.pcs.GetCollateralHash(opts, key)
+	if err != nil {
+		return common.Hash{}, logex.Trace(err)
+	}
+	return hash, nil
+}
+
+// TcbInfoCollateralHash returns the hash of the TCB info stored on chain
+func (p *Client) TcbInfoCollateralHash(ctx context.Context, tcbType uint8, fmspc [6]byte, tcbVersion uint32) (common.Hash, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	key, err := p.fmspc.FMSPCTCBKEY(opts, tcbType, fmspc, tcbVersion)
+	if err != nil {
+		return common.Hash{}, logex.Trace(err)
+	}
+	hash, err := p.fmspc.GetCollateralHash(opts, key)
+	if err != nil {
+		return common.Hash{}, logex.Trace(err)
+	}
+	return hash, nil
+}
+
+// EnclaveIDCollateralHash returns the hash of the enclave identity stored on chain
+func (p *Client) EnclaveIDCollateralHash(ctx context.Context, enclaveId uint8, version uint32) (common.Hash, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	key, err := p.enclaveId.ENCLAVEIDKEY(opts, big.NewInt(int64(enclaveId)), big.NewInt(int64(version)))
+	if err != nil {
+		return common.Hash{}, logex.Trace(err)
+	}
+	hash, err := p.enclaveId.GetCollateralHash(opts, key)
+	if err != nil {
+		return common.Hash{}, logex.Trace(err)
+	}
+	return hash, nil
+}
@@ -0,0 +1,424 @@
+// Package plonk verifies the PLONK proofs of gnark over bn254 the same way as its solidity verifier
+package plonk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/chzyer/logex"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+)
+
+var (
+	ErrInvalidProof        = logex.Define("invalid plonk proof")
+	ErrVerification        = logex.Define("plonk verification failed")
+	ErrInvalidVerifyingKey = logex.Define("invalid plonk verifying key")
+)
+
+const (
+	// PROOF_SIZE is the size of the solidity encoding of a proof without custom gates,
+	// each custom gate adds its opening(32) and its commitment(64)
+	PROOF_SIZE = 0x300
+	// BSB22_DST is the domain of the hash of the custom gate commitments
+	BSB22_DST = "BSB22-Plonk"
+
+	// kzgLinesSize is the size of the precomputed lines of the kzg G2 points written by the newer gnark versions,
+	// 2 points * 2 lines * 66 steps of the miller loop * 2 E2 elements
+	kzgLinesSize = 2 * 2 * 66 * 2 * 2 * fp.Bytes
+)
+
+// VerifyingKey is the verifying key of a circuit, with one custom gate per commitment
+type VerifyingKey struct {
+	// Size is the size of the evaluation domain, SizeInv is its inverse
+	Size    uint64
+	SizeInv fr.Element
+	// Generator of the evaluation domain
+	Generator         fr.Element
+	NbPublicVariables uint64
+	// CosetShift is the shift of the cosets of the permutation
+	CosetShift fr.Element
+
+	// S are the commitments of the permutation
+	S                  [3]bn254.G1Affine
+	Ql, Qr, Qm, Qo, Qk bn254.G1Affine
+	// Qcp are the commitments of the custom gates
+	Qcp []bn254.G1Affine
+	Kzg kzg.VerifyingKey
+	// CommitmentConstraintIndexes are the constraints of the custom gates
+	CommitmentConstraintIndexes []uint64
+}
+
+// Proof is a decoded proof, the opening of the linearised polynomial is recomputed by the verifier
+type Proof struct {
+	// LRO are the commitments of the wires
+	LRO [3]bn254.G1Affine
+	// H are the commitments of the quotient
+	H [3]bn254.G1Affine
+	// Z is the commitment of the grand product
+	Z bn254.G1Affine
+	// ZShiftedOpening opens Z at ζω
+	ZShiftedOpening kzg.OpeningProof
+	// BatchedProof opens the linearised polynomial, l, r, o, s1, s2 and the custom gates at ζ
+	BatchedProof     kzg.BatchOpeningProof
+	Bsb22Commitments []bn254.G1Affine
+}
+
+// ParseGnarkVerifyingKey decodes the verifying key serialized by gnark with compressed points,
+// e.g. plonk_vk.bin of the SP1 circuits.
+//
+// Layout: size(u64) | size inv(fr) | generator(fr) | public variables(u64) | coset shift(fr) | s(g1) * 3 |
+// ql, qr, qm, qo, qk(g1) | len(qcp)(u32) | qcp(g1)... | kzg g1 | kzg g2 * 2 | [kzg lines] |
+// len(commitment constraint indexes)(u32) | indexes(u64)...
+func ParseGnarkVerifyingKey(data []byte) (*VerifyingKey, error) {
+	r := bytes.NewReader(data)
+	dec := bn254.NewDecoder(r)
+	vk := new(VerifyingKey)
+	for _, v := range []interface{}{
+		&vk.Size, &vk.SizeInv, &vk.Generator, &vk.NbPublicVariables, &vk.CosetShift,
+		&vk.S[0], &vk.S[1], &vk.S[2], &vk.Ql, &vk.Qr, &vk.Qm, &vk.Qo, &vk.Qk, &vk.Qcp,
+		&vk.Kzg.G1, &vk.Kzg.G2[0], &vk.Kzg.G2[1],
+	} {
+		if err := dec.Decode(v); err != nil {
+			return nil, ErrInvalidVerifyingKey.Trace(err)
+		}
+	}
+	rest := data[len(data)-r.Len():]
+	if !isIndexes(rest) && len(rest) > kzgLinesSize && isIndexes(rest[kzgLinesSize:]) {
+		// the lines are precomputed from G2, they aren't needed
+		rest = rest[kzgLinesSize:]
+	}
+	if !isIndexes(rest) {
+		return nil, ErrInvalidVerifyingKey.Trace("commitment constraint indexes", len(rest))
+	}
+	if err := bn254.NewDecoder(bytes.NewReader(rest)).Decode(&vk.CommitmentConstraintIndexes); err != nil {
+		return nil, ErrInvalidVerifyingKey.Trace(err)
+	}
+	if err := vk.validate(); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return vk, nil
+}
+
+// isIndexes tells whether data is exactly a []uint64 of the encoder
+func isIndexes(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	return uint64(len(data)-4) == 8*uint64(binary.BigEndian.Uint32(data))
+}
+
+func (vk *VerifyingKey) validate() error {
+	if vk.Size == 0 || vk.Size&(vk.Size-1) != 0 {
+		return ErrInvalidVerifyingKey.Trace("size", vk.Size)
+	}
+	var size, one, power fr.Element
+	size.SetUint64(vk.Size)
+	one.SetOne()
+	if size.Mul(&size, &vk.SizeInv); !size.Equal(&one) {
+		return ErrInvalidVerifyingKey.Trace("size inv")
+	}
+	if power.Exp(vk.Generator, new(big.Int).SetUint64(vk.Size)); !power.Equal(&one) {
+		return ErrInvalidVerifyingKey.Trace("generator")
+	}
+	if len(vk.Qcp) != len(vk.CommitmentConstraintIndexes) {
+		return ErrInvalidVerifyingKey.Trace("custom gates", len(vk.Qcp), len(vk.CommitmentConstraintIndexes))
+	}
+	return nil
+}
+
+// ParseProof decodes the proof in the solidity encoding of gnark:
+//
+//	l, r, o(g1) | h0, h1, h2(g1) | l, r, o, s1, s2 at ζ(fr) | z(g1) | z at ζω(fr) |
+//	opening at ζ(g1) | opening at ζω(g1) | custom gates at ζ(fr)... | custom gate commitments(g1)...
+//
+// The points are uncompressed.
+func (vk *VerifyingKey) ParseProof(data []byte) (*Proof, error) {
+	nbCommitments := len(vk.Qcp)
+	if len(data) != PROOF_SIZE+nbCommitments*(fr.Bytes+bn254.SizeOfG1AffineUncompressed) {
+		return nil, ErrInvalidProof.Trace(len(data))
+	}
+	r := &proofReader{data: data}
+	proof := &Proof{Bsb22Commitments: make([]bn254.G1Affine, nbCommitments)}
+	proof.BatchedProof.ClaimedValues = make([]fr.Element, 6+nbCommitments)
+	for i := range proof.LRO {
+		r.g1(&proof.LRO[i])
+	}
+	for i := range proof.H {
+		r.g1(&proof.H[i])
+	}
+	for i := 1; i < 6; i++ {
+		r.fr(&proof.BatchedProof.ClaimedValues[i])
+	}
+	r.g1(&proof.Z)
+	r.fr(&proof.ZShiftedOpening.ClaimedValue)
+	r.g1(&proof.BatchedProof.H)
+	r.g1(&proof.ZShiftedOpening.H)
+	for i := 0; i < nbCommitments; i++ {
+		r.fr(&proof.BatchedProof.ClaimedValues[6+i])
+	}
+	for i := range proof.Bsb22Commitments {
+		r.g1(&proof.Bsb22Commitments[i])
+	}
+	if r.err != nil {
+		return nil, ErrInvalidProof.Trace(r.err)
+	}
+	return proof, nil
+}
+
+type proofReader struct {
+	data []byte
+	err  error
+}
+
+func (r *proofReader) next(n int) []byte {
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out
+}
+
+func (r *proofReader) g1(p *bn254.G1Affine) {
+	data := r.next(bn254.SizeOfG1AffineUncompressed)
+	if r.err != nil {
+		return
+	}
+	// the flags of the compressed encoding are never set in the coordinates
+	if data[0]&0xc0 != 0 {
+		r.err = logex.NewErrorf("invalid point: %x", data)
+		return
+	}
+	if _, err := p.SetBytes(data); err != nil {
+		r.err = logex.Trace(err)
+	}
+}
+
+func (r *proofReader) fr(e *fr.Element) {
+	data := r.next(fr.Bytes)
+	if r.err != nil {
+		return
+	}
+	if err := e.SetBytesCanonical(data); err != nil {
+		r.err = logex.Trace(err)
+	}
+}
+
+// Verify checks the proof of the public inputs, the proof is in the solidity encoding, see ParseProof
+func (vk *VerifyingKey) Verify(proof []byte, inputs []*big.Int) error {
+	if uint64(len(inputs)) != vk.NbPublicVariables {
+		return ErrInvalidProof.Trace("public inputs", len(inputs))
+	}
+	publicWitness := make([]fr.Element, len(inputs))
+	for i, input := range inputs {
+		if input.Sign() < 0 || input.Cmp(fr.Modulus()) >= 0 {
+			return ErrInvalidProof.Trace("public input out of range")
+		}
+		publicWitness[i].SetBigInt(input)
+	}
+	parsed, err := vk.ParseProof(proof)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if err := vk.verify(parsed, publicWitness); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+// Challenges are the Fiat-Shamir challenges of a proof
+type Challenges struct {
+	Gamma, Beta, Alpha, Zeta fr.Element
+}
+
+// DeriveChallenges computes the challenges from the verifying key, the public inputs and the commitments of the proof
+func (vk *VerifyingKey) DeriveChallenges(proof *Proof, publicWitness []fr.Element) (*Challenges, error) {
+	fs := fiatshamir.NewTranscript(sha256.New(), "gamma", "beta", "alpha", "zeta")
+	// gamma binds the public data and the wires
+	gammaDeps := []*bn254.G1Affine{&vk.S[0], &vk.S[1], &vk.S[2], &vk.Ql, &vk.Qr, &vk.Qm, &vk.Qo, &vk.Qk}
+	for i := range vk.Qcp {
+		gammaDeps = append(gammaDeps, &vk.Qcp[i])
+	}
+	for _, p := range gammaDeps {
+		if err := fs.Bind("gamma", p.Marshal()); err != nil {
+			return nil, logex.Trace(err)
+		}
+	}
+	for i := range publicWitness {
+		if err := fs.Bind("gamma", publicWitness[i].Marshal()); err != nil {
+			return nil, logex.Trace(err)
+		}
+	}
+	alphaDeps := make([]*bn254.G1Affine, 0, len(proof.Bsb22Commitments)+1)
+	for i := range proof.Bsb22Commitments {
+		alphaDeps = append(alphaDeps, &proof.Bsb22Commitments[i])
+	}
+	alphaDeps = append(alphaDeps, &proof.Z)
+
+	var c Challenges
+	for _, item := range []struct {
+		id    string
+		value *fr.Element
+		deps  []*bn254.G1Affine
+	}{
+		{"gamma", &c.Gamma, []*bn254.G1Affine{&proof.LRO[0], &proof.LRO[1], &proof.LRO[2]}},
+		{"beta", &c.Beta, nil},
+		{"alpha", &c.Alpha, alphaDeps},
+		{"zeta", &c.Zeta, []*bn254.G1Affine{&proof.H[0], &proof.H[1], &proof.H[2]}},
+	} {
+		for _, p := range item.deps {
+			if err := fs.Bind(item.id, p.Marshal()); err != nil {
+				return nil, logex.Trace(err)
+			}
+		}
+		value, err := fs.ComputeChallenge(item.id)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		item.value.SetBytes(value)
+	}
+	return &c, nil
+}
+
+// Linearise computes the opening of the linearised polynomial at ζ, which is set in the proof,
+// and the commitment of the linearised polynomial
+func (vk *VerifyingKey) Linearise(proof *Proof, publicWitness []fr.Element, c *Challenges) (bn254.G1Affine, error) {
+	var digest bn254.G1Affine
+	zeta, alpha, beta, gamma := c.Zeta, c.Alpha, c.Beta, c.Gamma
+	one := fr.One()
+
+	// ζⁿ-1 and L₁(ζ) = (ζⁿ-1)/(n(ζ-1))
+	var zetaPowerN, zhZeta, lagrangeOne fr.Element
+	zetaPowerN.Exp(zeta, new(big.Int).SetUint64(vk.Size))
+	zhZeta.Sub(&zetaPowerN, &one)
+	lagrangeOne.Sub(&zeta, &one).
+		Inverse(&lagrangeOne).
+		Mul(&lagrangeOne, &zhZeta).
+		Mul(&lagrangeOne, &vk.SizeInv)
+
+	// PI(ζ) = ∑ᵢ Lᵢ(ζ)wᵢ, Lᵢ(ζ) = ωⁱ/n (ζⁿ-1)/(ζ-ωⁱ)
+	var pi, accw, xiLi fr.Element
+	dens := make([]fr.Element, len(publicWitness))
+	accw.SetOne()
+	for i := range publicWitness {
+		dens[i].Sub(&zeta, &accw)
+		accw.Mul(&accw, &vk.Generator)
+	}
+	invDens := fr.BatchInvert(dens)
+	accw.SetOne()
+	for i := range publicWitness {
+		xiLi.Mul(&zhZeta, &invDens[i]).
+			Mul(&xiLi, &vk.SizeInv).
+			Mul(&xiLi, &accw).
+			Mul(&xiLi, &publicWitness[i])
+		accw.Mul(&accw, &vk.Generator)
+		pi.Add(&pi, &xiLi)
+	}
+	// the custom gates add the hash of their commitment at their constraint
+	var wPowI, den, lagrange fr.Element
+	for i, index := range vk.CommitmentConstraintIndexes {
+		hashed, err := fr.Hash(proof.Bsb22Commitments[i].Marshal(), []byte(BSB22_DST), 1)
+		if err != nil {
+			return digest, logex.Trace(err)
+		}
+		wPowI.Exp(vk.Generator, new(big.Int).SetUint64(vk.NbPublicVariables+index))
+		den.Sub(&zeta, &wPowI)
+		lagrange.SetOne().
+			Sub(&zeta, &lagrange).
+			Mul(&lagrange, &wPowI).
+			Div(&lagrange, &den).
+			Mul(&lagrange, &lagrangeOne)
+		xiLi.Mul(&lagrange, &hashed[0])
+		pi.Add(&pi, &xiLi)
+	}
+
+	claimed := proof.BatchedProof.ClaimedValues
+	l, r, o, s1, s2 := claimed[1], claimed[2], claimed[3], claimed[4], claimed[5]
+	zu := proof.ZShiftedOpening.ClaimedValue
+
+	var alphaSquareLagrangeOne, tmp fr.Element
+	alphaSquareLagrangeOne.Mul(&lagrangeOne, &alpha).Mul(&alphaSquareLagrangeOne, &alpha)
+
+	// the opening is -[PI(ζ) - α²L₁(ζ) + α(l(ζ)+βs1(ζ)+γ)(r(ζ)+βs2(ζ)+γ)(o(ζ)+γ)z(ωζ)]
+	var constLin fr.Element
+	constLin.Mul(&beta, &s1).Add(&constLin, &gamma).Add(&constLin, &l)
+	tmp.Mul(&s2, &beta).Add(&tmp, &gamma).Add(&tmp, &r)
+	constLin.Mul(&constLin, &tmp)
+	tmp.Add(&o, &gamma)
+	constLin.Mul(&tmp, &constLin).Mul(&constLin, &alpha).Mul(&constLin, &zu)
+	constLin.Sub(&constLin, &alphaSquareLagrangeOne).Add(&constLin, &pi)
+	constLin.Neg(&constLin)
+	claimed[0] = constLin
+
+	// _s1 = α(l(ζ)+βs1(ζ)+γ)(r(ζ)+βs2(ζ)+γ)βz(ωζ)
+	var _s1, _s2 fr.Element
+	_s1.Mul(&beta, &s1).Add(&_s1, &l).Add(&_s1, &gamma)
+	tmp.Mul(&beta, &s2).Add(&tmp, &r).Add(&tmp, &gamma)
+	_s1.Mul(&_s1, &tmp).Mul(&_s1, &beta).Mul(&_s1, &alpha).Mul(&_s1, &zu)
+
+	// _s2 = -α(l(ζ)+βζ+γ)(r(ζ)+βuζ+γ)(o(ζ)+βu²ζ+γ)
+	_s2.Mul(&beta, &zeta).Add(&_s2, &gamma).Add(&_s2, &l)
+	tmp.Mul(&beta, &vk.CosetShift).Mul(&tmp, &zeta).Add(&tmp, &gamma).Add(&tmp, &r)
+	_s2.Mul(&_s2, &tmp)
+	tmp.Mul(&beta, &vk.CosetShift).Mul(&tmp, &vk.CosetShift).Mul(&tmp, &zeta).Add(&tmp, &o).Add(&tmp, &gamma)
+	_s2.Mul(&_s2, &tmp).Mul(&_s2, &alpha).Neg(&_s2)
+
+	var coeffZ, rl fr.Element
+	coeffZ.Add(&alphaSquareLagrangeOne, &_s2)
+	rl.Mul(&l, &r)
+
+	// -ζⁿ⁺²(ζⁿ-1), -ζ²⁽ⁿ⁺²⁾(ζⁿ-1), -(ζⁿ-1)
+	var zetaNPlusTwoZh, zetaNPlusTwoSquareZh, zh fr.Element
+	zetaNPlusTwoZh.Exp(zeta, new(big.Int).SetUint64(vk.Size+2))
+	zetaNPlusTwoSquareZh.Mul(&zetaNPlusTwoZh, &zetaNPlusTwoZh)
+	zetaNPlusTwoZh.Mul(&zetaNPlusTwoZh, &zhZeta).Neg(&zetaNPlusTwoZh)
+	zetaNPlusTwoSquareZh.Mul(&zetaNPlusTwoSquareZh, &zhZeta).Neg(&zetaNPlusTwoSquareZh)
+	zh.Neg(&zhZeta)
+
+	points := append([]bn254.G1Affine{}, proof.Bsb22Commitments...)
+	points = append(points, vk.Ql, vk.Qr, vk.Qm, vk.Qo, vk.Qk, vk.S[2], proof.Z, proof.H[0], proof.H[1], proof.H[2])
+	scalars := append([]fr.Element{}, claimed[6:]...)
+	scalars = append(scalars, l, r, rl, o, one, _s1, coeffZ, zh, zetaNPlusTwoZh, zetaNPlusTwoSquareZh)
+	if _, err := digest.MultiExp(points, scalars, ecc.MultiExpConfig{}); err != nil {
+		return digest, logex.Trace(err)
+	}
+	return digest, nil
+}
+
+// FoldedDigests are the commitments opened at ζ by BatchedProof, in the order of its claimed values
+func (vk *VerifyingKey) FoldedDigests(proof *Proof, linearised bn254.G1Affine) []kzg.Digest {
+	digests := []kzg.Digest{linearised, proof.LRO[0], proof.LRO[1], proof.LRO[2], vk.S[0], vk.S[1]}
+	return append(digests, vk.Qcp...)
+}
+
+func (vk *VerifyingKey) verify(proof *Proof, publicWitness []fr.Element) error {
+	c, err := vk.DeriveChallenges(proof, publicWitness)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	linearised, err := vk.Linearise(proof, publicWitness, c)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	zu := proof.ZShiftedOpening.ClaimedValue
+	foldedProof, foldedDigest, err := kzg.FoldProof(vk.FoldedDigests(proof, linearised), &proof.BatchedProof, c.Zeta, sha256.New(), zu.Marshal())
+	if err != nil {
+		return ErrInvalidProof.Trace(err)
+	}
+	var shiftedZeta fr.Element
+	shiftedZeta.Mul(&c.Zeta, &vk.Generator)
+	err = kzg.BatchVerifyMultiPoints(
+		[]kzg.Digest{foldedDigest, proof.Z},
+		[]kzg.OpeningProof{foldedProof, proof.ZShiftedOpening},
+		[]fr.Element{c.Zeta, shiftedZeta},
+		vk.Kzg,
+	)
+	if err != nil {
+		return ErrVerification.Trace(err)
+	}
+	return nil
+}
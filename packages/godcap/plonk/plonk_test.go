@@ -0,0 +1,66 @@
+package plonk_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/plonk"
+	"github.com/automata-network/dcap-sdk/packages/godcap/testutil"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+func TestVerify(t *testing.T) {
+	defer test.New(t)
+	for _, commitments := range []int{0, 1} {
+		vk, td, err := testutil.NewPlonkTestKey(2, commitments)
+		test.Nil(err)
+		inputs := []*big.Int{big.NewInt(1), big.NewInt(2)}
+		proof, err := td.Prove(inputs)
+		test.Nil(err)
+		test.Equal(len(proof), plonk.PROOF_SIZE+commitments*96)
+		test.Nil(vk.Verify(proof, inputs))
+
+		test.True(logex.Equal(vk.Verify(proof, []*big.Int{big.NewInt(1), big.NewInt(3)}), plonk.ErrVerification))
+		test.True(logex.Equal(vk.Verify(proof, inputs[:1]), plonk.ErrInvalidProof))
+		test.True(logex.Equal(vk.Verify(proof[:len(proof)-1], inputs), plonk.ErrInvalidProof))
+		test.True(logex.Equal(vk.Verify(proof, []*big.Int{fr.Modulus(), big.NewInt(2)}), plonk.ErrInvalidProof))
+		// the opening of l at ζ
+		tampered := append([]byte{}, proof...)
+		tampered[0x180+31] ^= 1
+		test.True(logex.Equal(vk.Verify(tampered, inputs), plonk.ErrVerification))
+		// not a point
+		tampered = append([]byte{}, proof...)
+		tampered[63] ^= 1
+		test.True(logex.Equal(vk.Verify(tampered, inputs), plonk.ErrInvalidProof))
+	}
+}
+
+func TestParseGnarkVerifyingKey(t *testing.T) {
+	defer test.New(t)
+	vk, td, err := testutil.NewPlonkTestKey(2, 1)
+	test.Nil(err)
+	inputs := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	proof, err := td.Prove(inputs)
+	test.Nil(err)
+
+	for _, lines := range []bool{false, true} {
+		data, err := testutil.MarshalPlonkVerifyingKey(vk, lines)
+		test.Nil(err)
+		parsed, err := plonk.ParseGnarkVerifyingKey(data)
+		test.Nil(err)
+		test.Equal(parsed, vk)
+		test.Nil(parsed.Verify(proof, inputs))
+
+		_, err = plonk.ParseGnarkVerifyingKey(data[:len(data)-1])
+		test.True(logex.Equal(err, plonk.ErrInvalidVerifyingKey))
+	}
+
+	invalid := *vk
+	invalid.Size = 1000
+	data, err := testutil.MarshalPlonkVerifyingKey(&invalid, false)
+	test.Nil(err)
+	_, err = plonk.ParseGnarkVerifyingKey(data)
+	test.True(logex.Equal(err, plonk.ErrInvalidVerifyingKey))
+}
@@ -5,9 +5,11 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
@@ -33,10 +35,12 @@ var (
 	ErrValueShouldBeNil        = logex.Define("value in TransactOpts should be nil")
 	ErrTransactOptsMissingFrom = logex.Define("TransactOpts missing from")
 	ErrInsuccifientFunds       = logex.Define("InsuccifientFunds")
+	ErrProgramNotAccepted      = logex.Define("program %v of zkType %v is not accepted on chain %v")
 	DcapError                  = map[string]string{
 		"0x1356a63b": "AutomataDcapAttestation: BP_Not_Valid()",
 		"0x1a72054d": "AutomataDcapAttestation: Insuccifient_Funds()",
 		"0xc40a532b": "AutomataDcapAttestation: Withdrawal_Failed()",
+		"0x1bce0e73": "MockDcapAttestation: Invalid_Mock_Proof()",
 	}
 )
 
@@ -118,6 +122,15 @@ func WithZkProof(cfg *zkdcap.ZkProofConfig) DcapPortalOption {
 	}
 }
 
+// WithZkProgramCheck enables checking the program of the prover against the attestation contract
+// before generating the proofs, it's enabled by default
+func WithZkProgramCheck(enabled bool) DcapPortalOption {
+	return func(ctx context.Context, p *DcapPortal) error {
+		p.skipProgramCheck = !enabled
+		return nil
+	}
+}
+
 // DcapPortal represents the main interface for interacting with DCAP attestation
 type DcapPortal struct {
 	client     *ethclient.Client
@@ -132,6 +145,11 @@ type DcapPortal struct {
 	pccs    *pccs.Client
 
 	zkProof *zkdcap.ZkProofClient
+
+	// skipProgramCheck disables CheckZkProgram, acceptedPrograms caches the programs accepted by the chain
+	skipProgramCheck bool
+	programsMu       sync.Mutex
+	acceptedPrograms map[zkdcap.ZkType]string
 }
 
 // NewDcapPortal creates a new instance of DcapPortal with the provided options.
@@ -211,6 +229,11 @@ func (d *DcapPortal) Pccs() *pccs.Client {
 	return d.pccs
 }
 
+// ZkProof returns the zkproof client associated with the DcapPortal.
+func (d *DcapPortal) ZkProof() *zkdcap.ZkProofClient {
+	return d.zkProof
+}
+
 // BuildTransactOpts builds transaction options using the provided private key.
 // Returns error if key transactor creation or options normalization fails.
 func (p *DcapPortal) BuildTransactOpts(ctx context.Context) (*bind.TransactOpts, error) {
@@ -296,12 +319,146 @@ func (p *DcapPortal) GenerateZkProof(ctx context.Context, ty zkdcap.ZkType, quot
 	if p.zkProof == nil {
 		return nil, logex.NewErrorf("DcapPortal should call EnableZkProof() frist")
 	}
+	if err := p.CheckZkProgram(ctx, ty); err != nil {
+		return nil, logex.Trace(err)
+	}
+	collateral, err := p.zkCollateral(ctx, ty, quote)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return p.zkProof.ProveQuote(ctx, ty, quote, collateral)
+}
+
+// AcceptedProgramIDs returns the program identifiers accepted by the zk verifier of the attestation contract
+func (p *DcapPortal) AcceptedProgramIDs(ctx context.Context, ty zkdcap.ZkType) ([]common.Hash, error) {
+	attestation, err := IDcapAttestation.NewIDcapAttestationCaller(p.chain.AutomataDcapAttestationFee, p.client)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	ids, err := attestation.ProgramIdentifiers(opts, uint8(ty))
+	if err != nil {
+		if !isMissingMethod(err) {
+			return nil, logex.Trace(err)
+		}
+		// the earlier deployments accept only one program
+		id, er := attestation.ProgramIdentifier(opts, uint8(ty))
+		if er != nil {
+			return nil, logex.NewErrorf("programIdentifiers: %v, programIdentifier: %v", err, er)
+		}
+		ids = [][32]byte{id}
+	}
+	accepted := make([]common.Hash, len(ids))
+	for idx, id := range ids {
+		accepted[idx] = id
+	}
+	return accepted, nil
+}
+
+// isMissingMethod reports whether the call reverted or returned nothing, as a method missing in the deployment does
+func isMissingMethod(err error) bool {
+	var jerr JsonError
+	if errors.As(err, &jerr) && strings.Contains(jerr.Error(), "execution reverted") {
+		return true
+	}
+	return strings.Contains(err.Error(), "attempting to unmarshal an empty string")
+}
+
+// CheckZkProgram returns ErrProgramNotAccepted if the chain rejects the program of the prover,
+// it's checked before generating the proofs unless it's disabled by WithZkProgramCheck.
+// The accepted programs are cached, the rejected ones are checked again.
+func (p *DcapPortal) CheckZkProgram(ctx context.Context, ty zkdcap.ZkType) error {
+	if p.zkProof == nil {
+		return logex.NewErrorf("DcapPortal should call EnableZkProof() frist")
+	}
+	if ty == zkdcap.ZkTypeMock {
+		// MockDcapAttestation has no program
+		return nil
+	}
+	if p.skipProgramCheck {
+		return nil
+	}
+	prover, err := p.zkProof.Prover(ty)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	p.programsMu.Lock()
+	cached, ok := p.acceptedPrograms[ty]
+	p.programsMu.Unlock()
+	if ok && cached == prover.ProgramID() {
+		return nil
+	}
+	accepted, err := p.AcceptedProgramIDs(ctx, ty)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	programId := common.HexToHash(prover.ProgramID())
+	for _, id := range accepted {
+		if id == programId {
+			p.programsMu.Lock()
+			if p.acceptedPrograms == nil {
+				p.acceptedPrograms = make(map[zkdcap.ZkType]string)
+			}
+			p.acceptedPrograms[ty] = prover.ProgramID()
+			p.programsMu.Unlock()
+			return nil
+		}
+	}
+	return ErrProgramNotAccepted.Format(prover.ProgramID(), ty, p.chainID)
+}
+
+// zkCollateral fetches the collateral of the quote if the prover needs it
+func (p *DcapPortal) zkCollateral(ctx context.Context, ty zkdcap.ZkType, quote []byte) (*zkdcap.Collateral, error) {
+	prover, err := p.zkProof.Prover(ty)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if !zkdcap.NeedsCollateral(prover) {
+		return nil, nil
+	}
 	parser := parser.NewQuoteParser(quote)
 	collateral, err := zkdcap.NewCollateralFromQuoteParser(ctx, parser, p.pccs)
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
-	return p.zkProof.ProveQuote(ctx, ty, quote, collateral)
+	return collateral, nil
+}
+
+// GenerateZkProofBatch generates zero-knowledge proofs for the quotes concurrently.
+// The results are streamed as they finish, see zkdcap.ZkProofClient.ProveBatch.
+func (p *DcapPortal) GenerateZkProofBatch(ctx context.Context, ty zkdcap.ZkType, quotes [][]byte, opts *zkdcap.BatchOptions) (<-chan *zkdcap.BatchResult, error) {
+	if p.zkProof == nil {
+		return nil, logex.NewErrorf("DcapPortal should call EnableZkProof() frist")
+	}
+	if err := p.CheckZkProgram(ctx, ty); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return p.zkProof.ProveBatch(ctx, ty, quotes, opts), nil
+}
+
+// SubmitZkProof starts generating the zero-knowledge proof for the given quote without waiting for it.
+// The returned job is saved into the JobStore of ZkProofConfig, use ResumeZkProof to get the proof.
+func (p *DcapPortal) SubmitZkProof(ctx context.Context, ty zkdcap.ZkType, quote []byte) (*zkdcap.ProofJob, error) {
+	if p.zkProof == nil {
+		return nil, logex.NewErrorf("DcapPortal should call EnableZkProof() frist")
+	}
+	if err := p.CheckZkProgram(ctx, ty); err != nil {
+		return nil, logex.Trace(err)
+	}
+	collateral, err := p.zkCollateral(ctx, ty, quote)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return p.zkProof.SubmitProof(ctx, ty, quote, collateral)
+}
+
+// ResumeZkProof waits for the zero-knowledge proof of a job returned by SubmitZkProof,
+// the job can be loaded from the JobStore after a restart.
+func (p *DcapPortal) ResumeZkProof(ctx context.Context, job *zkdcap.ProofJob) (*zkdcap.ZkProof, error) {
+	if p.zkProof == nil {
+		return nil, logex.NewErrorf("DcapPortal should call EnableZkProof() frist")
+	}
+	return p.zkProof.ResumeProof(ctx, job)
 }
 
 // VerifyAndAttestWithZKProof verifies and attests the ZK proof on chain.
@@ -315,6 +472,23 @@ func (p *DcapPortal) VerifyAndAttestWithZKProof(opts *bind.TransactOpts, zkProof
 		}
 	}
 
+	if !zkProof.Mode.OnChain() {
+		return nil, zkdcap.ErrProofModeNotOnChain.Format(zkProof.Mode)
+	}
+	if p.zkProof != nil {
+		// don't pay for a proof which will be rejected on chain
+		if err := p.zkProof.VerifyProof(zkProof); err != nil {
+			return nil, logex.Trace(err)
+		}
+		ctx := opts.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if err := p.zkProof.CheckFreshness(ctx, zkProof); err != nil {
+			return nil, logex.Trace(err)
+		}
+	}
+
 	params, err := callback.Abi()
 	if err != nil {
 		return nil, logex.Trace(err)
@@ -366,6 +540,24 @@ func (p *DcapPortal) CheckQuote(ctx context.Context, quote []byte) (bool, error)
 	return args[0].(bool), nil
 }
 
+// VerifyQuote verifies the quote by doing a simulated call and decodes the verified output.
+// Returns error if the quote is invalid.
+func (p *DcapPortal) VerifyQuote(ctx context.Context, quote []byte) (*parser.VerifiedOutput, error) {
+	args, err := p.callContract(ctx, &p.dcapAbi, "verifyAndAttestOnChain", quote)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	output := args[1].([]byte)
+	if !args[0].(bool) {
+		return nil, logex.NewErrorf("quote verification failed: %q", output)
+	}
+	verifiedOutput, err := parser.ParseVerifiedOutput(output)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return verifiedOutput, nil
+}
+
 // CheckZkProof verifies if a ZK proof is valid by doing a simulated call.
 // Returns true if proof is valid, false otherwise.
 func (p *DcapPortal) CheckZkProof(ctx context.Context, proof *zkdcap.ZkProof) (bool, error) {
@@ -168,3 +168,22 @@ func TestDcapPortalZkProof(t *testing.T) {
 		t.Fatal("verify zkproof failed")
 	}
 }
+
+// testJsonError is the error of a rpc call
+type testJsonError struct {
+	msg  string
+	data interface{}
+}
+
+func (e *testJsonError) Error() string          { return e.msg }
+func (e *testJsonError) ErrorCode() int         { return 3 }
+func (e *testJsonError) ErrorData() interface{} { return e.data }
+
+func TestIsMissingMethod(t *testing.T) {
+	defer test.New(t)
+	test.True(isMissingMethod(&testJsonError{msg: "execution reverted"}))
+	test.True(isMissingMethod(logex.NewErrorf("abi: attempting to unmarshal an empty string while arguments are expected")))
+	// the transport errors aren't hidden by the fallback
+	test.True(!isMissingMethod(context.DeadlineExceeded))
+	test.True(!isMissingMethod(&testJsonError{msg: "429 Too Many Requests"}))
+}
@@ -0,0 +1,71 @@
+package ratls
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chzyer/logex"
+)
+
+// Attester generates a DCAP quote carrying the given report data
+type Attester interface {
+	Quote(reportData [64]byte) ([]byte, error)
+}
+
+// AttesterFunc adapts a function to the Attester interface
+type AttesterFunc func(reportData [64]byte) ([]byte, error)
+
+func (f AttesterFunc) Quote(reportData [64]byte) ([]byte, error) {
+	return f(reportData)
+}
+
+// GramineAttester generates SGX quotes through the /dev/attestation pseudo-files of Gramine
+type GramineAttester struct {
+	// defaults to /dev/attestation
+	Dir string
+}
+
+func (a *GramineAttester) Quote(reportData [64]byte) ([]byte, error) {
+	dir := a.Dir
+	if dir == "" {
+		dir = "/dev/attestation"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user_report_data"), reportData[:], 0600); err != nil {
+		return nil, logex.Trace(err)
+	}
+	quote, err := os.ReadFile(filepath.Join(dir, "quote"))
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return quote, nil
+}
+
+// TsmAttester generates quotes through the configfs-tsm report interface of the Linux kernel,
+// which is available in TDX guests
+type TsmAttester struct {
+	// defaults to /sys/kernel/config/tsm/report
+	Dir string
+}
+
+func (a *TsmAttester) Quote(reportData [64]byte) ([]byte, error) {
+	dir := a.Dir
+	if dir == "" {
+		dir = "/sys/kernel/config/tsm/report"
+	}
+	entry := filepath.Join(dir, fmt.Sprintf("godcap-%v", time.Now().UnixNano()))
+	if err := os.Mkdir(entry, 0700); err != nil {
+		return nil, logex.Trace(err)
+	}
+	defer os.Remove(entry)
+
+	if err := os.WriteFile(filepath.Join(entry, "inblob"), reportData[:], 0600); err != nil {
+		return nil, logex.Trace(err)
+	}
+	quote, err := os.ReadFile(filepath.Join(entry, "outblob"))
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return quote, nil
+}
@@ -0,0 +1,168 @@
+package challenge
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/automata-network/dcap-sdk/packages/godcap/ratls"
+	"github.com/chzyer/logex"
+)
+
+const NONCE_SIZE = 32
+
+var (
+	ErrUnknownNonce    = logex.Define("unknown or already used nonce")
+	ErrNonceExpired    = logex.Define("nonce expired at %v")
+	ErrReportData      = logex.Define("report data mismatch: want %x, got %x")
+	ErrQuoteRejected   = logex.Define("quote is rejected by the verifier")
+	ErrMissingVerifier = logex.Define("QuoteVerifier is required")
+)
+
+// Challenge is issued by the verifier and answered by the attester
+type Challenge struct {
+	Nonce  []byte    `json:"nonce"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// Evidence is the answer of the attester to a challenge
+type Evidence struct {
+	Nonce []byte `json:"nonce"`
+	// the key bound into the quote, e.g. a DER encoded SubjectPublicKeyInfo
+	PublicKey []byte `json:"public_key"`
+	Quote     []byte `json:"quote"`
+}
+
+// ReportData returns SHA-256(nonce || pubKey) followed by zeros
+func ReportData(nonce []byte, pubKey []byte) [64]byte {
+	var reportData [64]byte
+	hasher := sha256.New()
+	hasher.Write(nonce)
+	hasher.Write(pubKey)
+	copy(reportData[:], hasher.Sum(nil))
+	return reportData
+}
+
+// Attester answers challenges with quotes binding the nonce and its public key
+type Attester struct {
+	Attester  ratls.Attester
+	PublicKey []byte
+}
+
+// Respond generates the evidence for the challenge
+func (a *Attester) Respond(challenge *Challenge) (*Evidence, error) {
+	quote, err := a.Attester.Quote(ReportData(challenge.Nonce, a.PublicKey))
+	if err != nil {
+		return nil, logex.Trace(err, "generateQuote")
+	}
+	return &Evidence{
+		Nonce:     challenge.Nonce,
+		PublicKey: a.PublicKey,
+		Quote:     quote,
+	}, nil
+}
+
+// Verifier issues single-use nonces and verifies the evidence answering them
+type Verifier struct {
+	// *godcap.DcapPortal or a local verifier
+	QuoteVerifier ratls.QuoteVerifier
+	// Policy checks the measurements of the quote, optional
+	Policy func(quote *parser.QuoteParser) error
+	// lifetime of a nonce, defaults to 5 minutes
+	TTL time.Duration
+	// defaults to time.Now
+	Now func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+// NewChallenge issues a random nonce, expired nonces are pruned
+func (v *Verifier) NewChallenge() (*Challenge, error) {
+	nonce := make([]byte, NONCE_SIZE)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, logex.Trace(err)
+	}
+	ttl := v.TTL
+	if ttl == 0 {
+		ttl = 5 * time.Minute
+	}
+	now := v.now()
+	expiry := now.Add(ttl)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.nonces == nil {
+		v.nonces = make(map[string]time.Time)
+	}
+	for key, exp := range v.nonces {
+		if now.After(exp) {
+			delete(v.nonces, key)
+		}
+	}
+	v.nonces[hex.EncodeToString(nonce)] = expiry
+	return &Challenge{Nonce: nonce, Expiry: expiry}, nil
+}
+
+// consumeNonce removes the nonce so that it can't be replayed
+func (v *Verifier) consumeNonce(nonce []byte) error {
+	key := hex.EncodeToString(nonce)
+	v.mu.Lock()
+	expiry, ok := v.nonces[key]
+	delete(v.nonces, key)
+	v.mu.Unlock()
+	if !ok {
+		return ErrUnknownNonce.Trace()
+	}
+	if v.now().After(expiry) {
+		return ErrNonceExpired.Format(expiry)
+	}
+	return nil
+}
+
+// Verify consumes the nonce, checks the report data and the policy, and verifies the quote
+func (v *Verifier) Verify(ctx context.Context, evidence *Evidence) (*parser.QuoteParser, error) {
+	if v.QuoteVerifier == nil {
+		return nil, ErrMissingVerifier.Trace()
+	}
+	if err := v.consumeNonce(evidence.Nonce); err != nil {
+		return nil, logex.Trace(err)
+	}
+	quoteParser, err := ratls.ParseQuote(evidence.Quote)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	reportData, err := quoteParser.ReportData()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	expected := ReportData(evidence.Nonce, evidence.PublicKey)
+	if !bytes.Equal(reportData[:], expected[:]) {
+		return nil, ErrReportData.Format(expected[:32], reportData)
+	}
+	if v.Policy != nil {
+		if err := v.Policy(quoteParser); err != nil {
+			return nil, logex.Trace(err, "policy")
+		}
+	}
+	valid, err := v.QuoteVerifier.CheckQuote(ctx, evidence.Quote)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if !valid {
+		return nil, ErrQuoteRejected.Trace()
+	}
+	return quoteParser, nil
+}
@@ -0,0 +1,90 @@
+package challenge
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/automata-network/dcap-sdk/packages/godcap/ratls"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+)
+
+// mockAttester patches the report data into a mock quote, the quote signature is no longer valid
+func mockAttester(quote []byte) ratls.Attester {
+	return ratls.AttesterFunc(func(reportData [64]byte) ([]byte, error) {
+		quote := append([]byte(nil), quote...)
+		p := parser.NewQuoteParser(quote)
+		offset := parser.QUOTE_HEADER_SIZE + parser.ENCLAVE_REPORT_SIZE - 64
+		if p.IsTdx() {
+			offset = parser.QUOTE_HEADER_SIZE + parser.TD_REPORT10_SIZE - 64
+		}
+		copy(quote[offset:], reportData[:])
+		return quote, nil
+	})
+}
+
+var acceptAll = ratls.QuoteVerifierFunc(func(ctx context.Context, quote []byte) (bool, error) {
+	return true, nil
+})
+
+func TestVerify(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	verifier := &Verifier{QuoteVerifier: acceptAll}
+	attester := &Attester{Attester: mockAttester(mock.Quotes[1]), PublicKey: []byte("pubkey")}
+
+	challenge, err := verifier.NewChallenge()
+	test.Nil(err)
+	evidence, err := attester.Respond(challenge)
+	test.Nil(err)
+	quote, err := verifier.Verify(ctx, evidence)
+	test.Nil(err)
+	test.True(quote.IsTdx())
+
+	// replay
+	_, err = verifier.Verify(ctx, evidence)
+	test.True(logex.Equal(err, ErrUnknownNonce))
+
+	// the quote is bound to another key
+	challenge, err = verifier.NewChallenge()
+	test.Nil(err)
+	evidence, err = attester.Respond(challenge)
+	test.Nil(err)
+	evidence.PublicKey = []byte("other")
+	_, err = verifier.Verify(ctx, evidence)
+	test.True(logex.Equal(err, ErrReportData))
+
+	// expired
+	challenge, err = verifier.NewChallenge()
+	test.Nil(err)
+	evidence, err = attester.Respond(challenge)
+	test.Nil(err)
+	verifier.Now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, err = verifier.Verify(ctx, evidence)
+	test.True(logex.Equal(err, ErrNonceExpired))
+}
+
+func TestHandler(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	verifier := &Verifier{QuoteVerifier: acceptAll}
+	attester := &Attester{Attester: mockAttester(mock.Quotes[0]), PublicKey: []byte("pubkey")}
+
+	verifierServer := httptest.NewServer(VerifierHandler(verifier))
+	defer verifierServer.Close()
+	test.Nil(attester.Attest(ctx, nil, verifierServer.URL))
+
+	attesterServer := httptest.NewServer(AttesterHandler(attester))
+	defer attesterServer.Close()
+	_, err := verifier.Challenge(ctx, nil, attesterServer.URL)
+	test.Nil(err)
+
+	verifier.Policy = func(quote *parser.QuoteParser) error {
+		return logex.NewErrorf("rejected")
+	}
+	test.NotNil(attester.Attest(ctx, nil, verifierServer.URL))
+}
@@ -0,0 +1,159 @@
+package challenge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/chzyer/logex"
+)
+
+const MAX_BODY_SIZE = 1 << 20
+
+// VerifyResult is the response of the verify endpoint
+type VerifyResult struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// VerifierHandler serves the verifier side of the protocol:
+//
+//	GET  {prefix}/challenge  issues a Challenge
+//	POST {prefix}/verify     verifies an Evidence, returns VerifyResult
+func VerifierHandler(v *Verifier) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/challenge"):
+			challenge, err := v.NewChallenge()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJson(w, http.StatusOK, challenge)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/verify"):
+			var evidence Evidence
+			if err := readJson(r, &evidence); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if _, err := v.Verify(r.Context(), &evidence); err != nil {
+				writeJson(w, http.StatusForbidden, &VerifyResult{Error: err.Error()})
+				return
+			}
+			writeJson(w, http.StatusOK, &VerifyResult{Valid: true})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// AttesterHandler answers a posted Challenge with an Evidence
+func AttesterHandler(a *Attester) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var challenge Challenge
+		if err := readJson(r, &challenge); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		evidence, err := a.Respond(&challenge)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJson(w, http.StatusOK, evidence)
+	})
+}
+
+// Attest pushes the evidence to a VerifierHandler mounted at baseUrl
+func (a *Attester) Attest(ctx context.Context, client *http.Client, baseUrl string) error {
+	var challenge Challenge
+	if err := doJson(ctx, client, http.MethodGet, baseUrl+"/challenge", nil, &challenge); err != nil {
+		return logex.Trace(err)
+	}
+	evidence, err := a.Respond(&challenge)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	var result VerifyResult
+	if err := doJson(ctx, client, http.MethodPost, baseUrl+"/verify", evidence, &result); err != nil {
+		return logex.Trace(err)
+	}
+	if !result.Valid {
+		return logex.NewErrorf("verification failed: %v", result.Error)
+	}
+	return nil
+}
+
+// Challenge pulls the evidence from an AttesterHandler at url and verifies it
+func (v *Verifier) Challenge(ctx context.Context, client *http.Client, url string) (*Evidence, error) {
+	challenge, err := v.NewChallenge()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	var evidence Evidence
+	if err := doJson(ctx, client, http.MethodPost, url, challenge, &evidence); err != nil {
+		return nil, logex.Trace(err)
+	}
+	if _, err := v.Verify(ctx, &evidence); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &evidence, nil
+}
+
+func readJson(r *http.Request, val interface{}) error {
+	if err := json.NewDecoder(io.LimitReader(r.Body, MAX_BODY_SIZE)).Decode(val); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+func writeJson(w http.ResponseWriter, status int, val interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(val)
+}
+
+func doJson(ctx context.Context, client *http.Client, method string, url string, body interface{}, result interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return logex.Trace(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MAX_BODY_SIZE))
+	if err != nil {
+		return logex.Trace(err)
+	}
+	// the verify endpoint reports rejections with a VerifyResult body
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusForbidden {
+		return logex.NewErrorf("%v %v: %v %s", method, url, resp.Status, bytes.TrimSpace(data))
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return logex.Trace(err, string(data))
+	}
+	return nil
+}
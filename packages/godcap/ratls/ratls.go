@@ -0,0 +1,276 @@
+package ratls
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/chzyer/logex"
+)
+
+// OidRaTlsQuote is the X.509 extension carrying the DCAP quote, as used by Intel RA-TLS and Gramine
+var OidRaTlsQuote = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 0}
+
+// OidTcgDiceTaggedEvidence is the CBOR tagged evidence of the interoperable RA-TLS stacks.
+// It's not supported, the peers are rejected with ErrUnsupportedEvidence unless they also embed OidRaTlsQuote.
+var OidTcgDiceTaggedEvidence = asn1.ObjectIdentifier{2, 23, 133, 5, 4, 9}
+
+var (
+	ErrMissingQuote        = logex.Define("RA-TLS quote extension is missing")
+	ErrUnsupportedEvidence = logex.Define("RA-TLS evidence %v is not supported, the quote is expected in %v")
+	ErrKeyNotBound         = logex.Define("public key hash %x is not bound into report data %x")
+	ErrQuoteRejected       = logex.Define("quote is rejected by the verifier")
+	ErrMissingPeer         = logex.Define("peer did not present a certificate")
+	ErrMissingVerifier     = logex.Define("QuoteVerifier is required")
+)
+
+// ReportData returns the report data binding the public key into the quote:
+// SHA-256 of the DER encoded SubjectPublicKeyInfo, followed by zeros
+func ReportData(pub crypto.PublicKey) ([64]byte, error) {
+	var reportData [64]byte
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return reportData, logex.Trace(err)
+	}
+	hash := sha256.Sum256(der)
+	copy(reportData[:], hash[:])
+	return reportData, nil
+}
+
+// CertOptions customizes the generated RA-TLS certificate
+type CertOptions struct {
+	// Key of the certificate, an ECDSA P-256 key is generated if nil
+	Key crypto.Signer
+	// defaults to "RATLS"
+	CommonName string
+	// defaults to 24 hours
+	Validity time.Duration
+}
+
+// GenerateCertificate creates a self-signed certificate whose public key is bound into the
+// report data of a quote generated by the attester. The quote is embedded under OidRaTlsQuote.
+func GenerateCertificate(attester Attester, opts *CertOptions) (*tls.Certificate, error) {
+	if opts == nil {
+		opts = new(CertOptions)
+	}
+	key := opts.Key
+	if key == nil {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		key = ecKey
+	}
+	commonName := opts.CommonName
+	if commonName == "" {
+		commonName = "RATLS"
+	}
+	validity := opts.Validity
+	if validity == 0 {
+		validity = 24 * time.Hour
+	}
+
+	reportData, err := ReportData(key.Public())
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	quote, err := attester.Quote(reportData)
+	if err != nil {
+		return nil, logex.Trace(err, "generateQuote")
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		ExtraExtensions: []pkix.Extension{
+			{Id: OidRaTlsQuote, Value: quote},
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// QuoteFromCertificate extracts the DCAP quote embedded in the certificate
+func QuoteFromCertificate(cert *x509.Certificate) ([]byte, error) {
+	taggedEvidence := false
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(OidRaTlsQuote) {
+			return ext.Value, nil
+		}
+		taggedEvidence = taggedEvidence || ext.Id.Equal(OidTcgDiceTaggedEvidence)
+	}
+	if taggedEvidence {
+		return nil, ErrUnsupportedEvidence.Format(OidTcgDiceTaggedEvidence, OidRaTlsQuote)
+	}
+	return nil, ErrMissingQuote.Trace()
+}
+
+// QuoteVerifier checks whether a DCAP quote is valid.
+// *godcap.DcapPortal implements it with an on-chain simulated call.
+type QuoteVerifier interface {
+	CheckQuote(ctx context.Context, quote []byte) (bool, error)
+}
+
+// QuoteVerifierFunc adapts a function, e.g. a local verifier, to the QuoteVerifier interface
+type QuoteVerifierFunc func(ctx context.Context, quote []byte) (bool, error)
+
+func (f QuoteVerifierFunc) CheckQuote(ctx context.Context, quote []byte) (bool, error) {
+	return f(ctx, quote)
+}
+
+// Verifier verifies RA-TLS certificates presented by peers
+type Verifier struct {
+	QuoteVerifier QuoteVerifier
+	// Policy checks the measurements of the quote, e.g. MRENCLAVE or RTMRs, optional
+	Policy func(quote *parser.QuoteParser) error
+	// Timeout of the quote verification during the TLS handshake, defaults to 30 seconds
+	Timeout time.Duration
+}
+
+// VerifyCertificate checks the self-signature, the key binding and the embedded quote.
+// Returns the verified quote.
+func (v *Verifier) VerifyCertificate(ctx context.Context, cert *x509.Certificate) ([]byte, error) {
+	if v.QuoteVerifier == nil {
+		return nil, ErrMissingVerifier.Trace()
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return nil, logex.Trace(err, "selfSigned")
+	}
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, logex.NewErrorf("certificate is not valid at %v", now)
+	}
+
+	quote, err := QuoteFromCertificate(cert)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	expected, err := ReportData(cert.PublicKey)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	quoteParser, err := ParseQuote(quote)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	reportData, err := quoteParser.ReportData()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if !bytes.Equal(reportData[:], expected[:]) {
+		return nil, ErrKeyNotBound.Format(expected[:32], reportData)
+	}
+	if v.Policy != nil {
+		if err := v.Policy(quoteParser); err != nil {
+			return nil, logex.Trace(err, "policy")
+		}
+	}
+
+	valid, err := v.QuoteVerifier.CheckQuote(ctx, quote)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if !valid {
+		return nil, ErrQuoteRejected.Trace()
+	}
+	return quote, nil
+}
+
+// VerifyPeerCertificate can be used as tls.Config.VerifyPeerCertificate
+func (v *Verifier) VerifyPeerCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return ErrMissingPeer.Trace()
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return logex.Trace(err)
+	}
+	timeout := v.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if _, err := v.VerifyCertificate(ctx, cert); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+// ServerConfig returns the tls.Config of a RA-TLS server.
+// Clients are required to present a RA-TLS certificate if verifier is not nil.
+func ServerConfig(cert *tls.Certificate, verifier *Verifier) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{*cert},
+	}
+	if verifier != nil {
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyPeerCertificate = verifier.VerifyPeerCertificate
+	}
+	return cfg
+}
+
+// ClientConfig returns the tls.Config of a RA-TLS client which verifies the server
+// certificate with the verifier. cert can be nil if the server does not require mutual attestation.
+// The verifier is required, the server certificate is self-signed.
+func ClientConfig(cert *tls.Certificate, verifier *Verifier) (*tls.Config, error) {
+	if verifier == nil || verifier.QuoteVerifier == nil {
+		return nil, ErrMissingVerifier.Trace()
+	}
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS13,
+		// the server certificate is self-signed, it's verified by VerifyPeerCertificate instead
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: verifier.VerifyPeerCertificate,
+	}
+	if cert != nil {
+		cfg.Certificates = []tls.Certificate{*cert}
+	}
+	return cfg, nil
+}
+
+// ParseQuote wraps parser.NewQuoteParser, recovering from its panic on malformed quotes
+func ParseQuote(quote []byte) (p *parser.QuoteParser, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = logex.NewErrorf("invalid quote: %v", r)
+		}
+	}()
+	if len(quote) < parser.QUOTE_HEADER_SIZE {
+		return nil, logex.NewErrorf("quote too short: %v", len(quote))
+	}
+	return parser.NewQuoteParser(quote), nil
+}
@@ -0,0 +1,110 @@
+package ratls
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+)
+
+// mockAttester patches the report data into a mock quote, the quote signature is no longer valid
+func mockAttester(quote []byte) Attester {
+	return AttesterFunc(func(reportData [64]byte) ([]byte, error) {
+		quote := append([]byte(nil), quote...)
+		p := parser.NewQuoteParser(quote)
+		offset := parser.QUOTE_HEADER_SIZE + parser.ENCLAVE_REPORT_SIZE - 64
+		if p.IsTdx() {
+			offset = parser.QUOTE_HEADER_SIZE + parser.TD_REPORT10_SIZE - 64
+		}
+		copy(quote[offset:], reportData[:])
+		return quote, nil
+	})
+}
+
+var acceptAll = QuoteVerifierFunc(func(ctx context.Context, quote []byte) (bool, error) {
+	return true, nil
+})
+
+func TestCertificate(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	verifier := &Verifier{QuoteVerifier: acceptAll}
+
+	for _, quote := range mock.Quotes {
+		cert, err := GenerateCertificate(mockAttester(quote), nil)
+		test.Nil(err)
+		verified, err := verifier.VerifyCertificate(ctx, cert.Leaf)
+		test.Nil(err)
+		test.True(bytes.Equal(verified[:parser.QUOTE_HEADER_SIZE], quote[:parser.QUOTE_HEADER_SIZE]))
+	}
+
+	// the quote is bound to another key
+	other, err := GenerateCertificate(mockAttester(mock.Quotes[0]), nil)
+	test.Nil(err)
+	cert, err := GenerateCertificate(AttesterFunc(func(reportData [64]byte) ([]byte, error) {
+		return QuoteFromCertificate(other.Leaf)
+	}), nil)
+	test.Nil(err)
+	_, err = verifier.VerifyCertificate(ctx, cert.Leaf)
+	test.NotNil(err)
+
+	rejectAll := &Verifier{QuoteVerifier: QuoteVerifierFunc(func(ctx context.Context, quote []byte) (bool, error) {
+		return false, nil
+	})}
+	_, err = rejectAll.VerifyCertificate(ctx, other.Leaf)
+	test.NotNil(err)
+
+	// the peers of the interoperable RA-TLS stacks are rejected explicitly
+	dice := &x509.Certificate{Extensions: []pkix.Extension{{Id: OidTcgDiceTaggedEvidence, Value: []byte{0xd9}}}}
+	_, err = QuoteFromCertificate(dice)
+	test.True(logex.Equal(err, ErrUnsupportedEvidence))
+	_, err = QuoteFromCertificate(&x509.Certificate{})
+	test.True(logex.Equal(err, ErrMissingQuote))
+}
+
+func TestHandshake(t *testing.T) {
+	defer test.New(t)
+
+	serverCert, err := GenerateCertificate(mockAttester(mock.Quotes[0]), nil)
+	test.Nil(err)
+	clientCert, err := GenerateCertificate(mockAttester(mock.Quotes[1]), nil)
+	test.Nil(err)
+
+	var clientQuoteTdx bool
+	serverVerifier := &Verifier{
+		QuoteVerifier: acceptAll,
+		Policy: func(quote *parser.QuoteParser) error {
+			clientQuoteTdx = quote.IsTdx()
+			return nil
+		},
+	}
+	clientVerifier := &Verifier{QuoteVerifier: acceptAll}
+
+	_, err = ClientConfig(clientCert, nil)
+	test.True(logex.Equal(err, ErrMissingVerifier))
+	clientConfig, err := ClientConfig(clientCert, clientVerifier)
+	test.Nil(err)
+
+	serverConn, clientConn := net.Pipe()
+	server := tls.Server(serverConn, ServerConfig(serverCert, serverVerifier))
+	client := tls.Client(clientConn, clientConfig)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Handshake()
+	}()
+	test.Nil(client.Handshake())
+	test.Nil(<-errCh)
+	test.True(clientQuoteTdx)
+
+	clientConn.Close()
+	serverConn.Close()
+}
@@ -0,0 +1,236 @@
+package sigstruct
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/chzyer/logex"
+)
+
+// SIGSTRUCT_SIZE is the size of the SIGSTRUCT produced by sgx_sign or gramine-sgx-sign
+const SIGSTRUCT_SIZE = 1808
+
+var led = binary.LittleEndian
+
+var (
+	ErrInvalidSize   = logex.Define("invalid SIGSTRUCT size: %v")
+	ErrInvalidHeader = logex.Define("invalid SIGSTRUCT header: %x")
+)
+
+var (
+	sigstructHeader  = []byte{0x06, 0x00, 0x00, 0x00, 0xE1, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+	sigstructHeader2 = []byte{0x01, 0x01, 0x00, 0x00, 0x60, 0x00, 0x00, 0x00, 0x60, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}
+)
+
+// SigStruct is the enclave signature structure (SDM Vol. 3D, 38.13)
+type SigStruct struct {
+	Header            [16]byte
+	Vendor            uint32
+	Date              uint32
+	Header2           [16]byte
+	SwDefined         uint32
+	Reserved1         [84]byte
+	Modulus           [384]byte
+	Exponent          uint32
+	Signature         [384]byte
+	MiscSelect        uint32
+	MiscMask          uint32
+	CetAttributes     uint8
+	CetAttributesMask uint8
+	Reserved2         [2]byte
+	IsvFamilyId       [16]byte
+	Attributes        [16]byte
+	AttributeMask     [16]byte
+	EnclaveHash       [32]byte
+	Reserved3         [16]byte
+	IsvExtProdId      [16]byte
+	IsvProdId         uint16
+	IsvSvn            uint16
+	Reserved4         [12]byte
+	Q1                [384]byte
+	Q2                [384]byte
+}
+
+// Parse decodes the `.sig` file generated by sgx_sign or gramine-sgx-sign
+func Parse(data []byte) (*SigStruct, error) {
+	if len(data) != SIGSTRUCT_SIZE {
+		return nil, ErrInvalidSize.Format(len(data))
+	}
+	var sig SigStruct
+	if err := binary.Read(bytes.NewReader(data), led, &sig); err != nil {
+		return nil, logex.Trace(err)
+	}
+	if !bytes.Equal(sig.Header[:], sigstructHeader) {
+		return nil, ErrInvalidHeader.Format(sig.Header)
+	}
+	if !bytes.Equal(sig.Header2[:], sigstructHeader2) {
+		return nil, ErrInvalidHeader.Format(sig.Header2)
+	}
+	return &sig, nil
+}
+
+// ParseFile reads and decodes a SIGSTRUCT file
+func ParseFile(fp string) (*SigStruct, error) {
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, logex.Trace(err, fp)
+	}
+	sig, err := Parse(data)
+	if err != nil {
+		return nil, logex.Trace(err, fp)
+	}
+	return sig, nil
+}
+
+// Bytes encodes the SIGSTRUCT
+func (s *SigStruct) Bytes() []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, SIGSTRUCT_SIZE))
+	binary.Write(buf, led, s)
+	return buf.Bytes()
+}
+
+// MrEnclave returns the expected MRENCLAVE of the enclave
+func (s *SigStruct) MrEnclave() [32]byte {
+	return s.EnclaveHash
+}
+
+// MrSigner returns the expected MRSIGNER, the SHA-256 of the little-endian modulus
+func (s *SigStruct) MrSigner() [32]byte {
+	return sha256.Sum256(s.Modulus[:])
+}
+
+// PublicKey returns the RSA key of the enclave signer
+func (s *SigStruct) PublicKey() *rsa.PublicKey {
+	return &rsa.PublicKey{
+		N: leInt(s.Modulus[:]),
+		E: int(s.Exponent),
+	}
+}
+
+// Verify checks the RSA signature over the signed fields of the SIGSTRUCT
+func (s *SigStruct) Verify() error {
+	// the signature covers header..swdefined and miscselect..isvsvn
+	raw := s.Bytes()
+	signed := make([]byte, 0, 256)
+	signed = append(signed, raw[0:128]...)
+	signed = append(signed, raw[900:1028]...)
+	digest := sha256.Sum256(signed)
+
+	sig := make([]byte, len(s.Signature))
+	for i := range sig {
+		sig[i] = s.Signature[len(sig)-1-i]
+	}
+	if err := rsa.VerifyPKCS1v15(s.PublicKey(), crypto.SHA256, digest[:], sig); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+// Measurements returns the identity of the enclave a verifier should expect
+func (s *SigStruct) Measurements() *Measurements {
+	mrSigner := s.MrSigner()
+	return &Measurements{
+		MrEnclave:     hex.EncodeToString(s.EnclaveHash[:]),
+		MrSigner:      hex.EncodeToString(mrSigner[:]),
+		IsvProdId:     s.IsvProdId,
+		IsvSvn:        s.IsvSvn,
+		MiscSelect:    s.MiscSelect,
+		MiscMask:      s.MiscMask,
+		Attributes:    hex.EncodeToString(s.Attributes[:]),
+		AttributeMask: hex.EncodeToString(s.AttributeMask[:]),
+		Date:          fmt.Sprintf("%08x", s.Date),
+	}
+}
+
+// Measurements is the JSON friendly form of the enclave identity in a SIGSTRUCT
+type Measurements struct {
+	MrEnclave     string `json:"mr_enclave"`
+	MrSigner      string `json:"mr_signer"`
+	IsvProdId     uint16 `json:"isv_prod_id"`
+	IsvSvn        uint16 `json:"isv_svn"`
+	MiscSelect    uint32 `json:"misc_select"`
+	MiscMask      uint32 `json:"misc_mask"`
+	Attributes    string `json:"attributes"`
+	AttributeMask string `json:"attribute_mask"`
+	Date          string `json:"date"`
+}
+
+// Mismatch is a field of the enclave report which differs from the SIGSTRUCT
+type Mismatch struct {
+	Field    string
+	Expected string
+	Actual   string
+}
+
+func (m *Mismatch) String() string {
+	return fmt.Sprintf("%v mismatch: expected=%v, actual=%v", m.Field, m.Expected, m.Actual)
+}
+
+// Compare checks the enclave report against the SIGSTRUCT.
+// Attributes and MISCSELECT are compared under their masks.
+// An empty result means the report was produced by the signed enclave.
+func (s *SigStruct) Compare(report *parser.EnclaveReport) []*Mismatch {
+	var mismatches []*Mismatch
+	add := func(field string, expected, actual interface{}) {
+		mismatches = append(mismatches, &Mismatch{
+			Field:    field,
+			Expected: fmt.Sprintf("%x", expected),
+			Actual:   fmt.Sprintf("%x", actual),
+		})
+	}
+
+	if report.MrEnclave != s.EnclaveHash {
+		add("MRENCLAVE", s.EnclaveHash, report.MrEnclave)
+	}
+	if mrSigner := s.MrSigner(); report.MrSigner != mrSigner {
+		add("MRSIGNER", mrSigner, report.MrSigner)
+	}
+	if report.IsvProdId != s.IsvProdId {
+		add("ISVPRODID", s.IsvProdId, report.IsvProdId)
+	}
+	if report.IsvSvn != s.IsvSvn {
+		add("ISVSVN", s.IsvSvn, report.IsvSvn)
+	}
+	if report.MiscSelect&s.MiscMask != s.MiscSelect&s.MiscMask {
+		add("MISCSELECT", s.MiscSelect&s.MiscMask, report.MiscSelect&s.MiscMask)
+	}
+	expected := maskBytes(s.Attributes[:], s.AttributeMask[:])
+	actual := maskBytes(report.Attributes[:], s.AttributeMask[:])
+	if !bytes.Equal(expected, actual) {
+		add("ATTRIBUTES", expected, actual)
+	}
+	return mismatches
+}
+
+// CompareQuote compares the enclave report of a SGX quote against the SIGSTRUCT
+func (s *SigStruct) CompareQuote(quote []byte) ([]*Mismatch, error) {
+	report, err := parser.NewQuoteParser(quote).EnclaveReport()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return s.Compare(report), nil
+}
+
+func maskBytes(data []byte, mask []byte) []byte {
+	out := make([]byte, len(data))
+	for i := range data {
+		out[i] = data[i] & mask[i]
+	}
+	return out
+}
+
+func leInt(data []byte) *big.Int {
+	be := make([]byte, len(data))
+	for i := range data {
+		be[i] = data[len(data)-1-i]
+	}
+	return new(big.Int).SetBytes(be)
+}
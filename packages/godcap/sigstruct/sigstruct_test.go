@@ -0,0 +1,79 @@
+package sigstruct
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/chzyer/test"
+)
+
+//go:embed test_enclave_sig.hex
+var testEnclaveSig string
+
+func testSigStruct() *SigStruct {
+	data, err := hex.DecodeString(testEnclaveSig)
+	test.Nil(err)
+	sig, err := Parse(data)
+	test.Nil(err)
+	return sig
+}
+
+func TestParse(t *testing.T) {
+	defer test.New(t)
+	sig := testSigStruct()
+
+	m := sig.Measurements()
+	test.Equal(m.MrEnclave, "70537bd5d5a9a6a27413d1c45eb478cdff35502a6de914020af90a4232ab6a5f")
+	test.Equal(m.MrSigner, "06dacefe996d2f277bc1fae1348d4cc6a2b19d1958129d6f4cfaca71b9a5b507")
+	test.Equal(m.IsvProdId, uint16(7))
+	test.Equal(m.IsvSvn, uint16(3))
+	test.Nil(sig.Verify())
+
+	sig.IsvSvn++
+	test.NotNil(sig.Verify())
+
+	_, err := Parse(make([]byte, SIGSTRUCT_SIZE))
+	test.NotNil(err)
+}
+
+func TestCompareQuote(t *testing.T) {
+	defer test.New(t)
+	sig := testSigStruct()
+
+	mismatches, err := sig.CompareQuote(mock.Quotes[0])
+	test.Nil(err)
+	test.True(len(mismatches) > 0)
+
+	// patch the mock SGX quote with the identity of the signed enclave
+	quote := append([]byte(nil), mock.Quotes[0]...)
+	report := quote[48:]
+	mrSigner := sig.MrSigner()
+	binary.LittleEndian.PutUint32(report[16:], sig.MiscSelect)
+	copy(report[48:], sig.Attributes[:])
+	copy(report[64:], sig.EnclaveHash[:])
+	copy(report[128:], mrSigner[:])
+	binary.LittleEndian.PutUint16(report[256:], sig.IsvProdId)
+	binary.LittleEndian.PutUint16(report[258:], sig.IsvSvn)
+
+	mismatches, err = sig.CompareQuote(quote)
+	test.Nil(err)
+	test.Equal(len(mismatches), 0)
+
+	// the DEBUG attribute is excluded by the attribute mask
+	report[48] |= 0x02
+	mismatches, err = sig.CompareQuote(quote)
+	test.Nil(err)
+	test.Equal(len(mismatches), 0)
+
+	binary.LittleEndian.PutUint16(report[258:], sig.IsvSvn+1)
+	mismatches, err = sig.CompareQuote(quote)
+	test.Nil(err)
+	test.Equal(len(mismatches), 1)
+	test.Equal(mismatches[0].Field, "ISVSVN")
+
+	_, err = sig.CompareQuote(mock.Quotes[1])
+	test.NotNil(err)
+}
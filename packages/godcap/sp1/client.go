@@ -1,12 +1,16 @@
 package sp1
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 
+	"github.com/automata-network/dcap-sdk/packages/godcap/observe"
 	"github.com/automata-network/dcap-sdk/packages/godcap/sp1/sp1_proto"
 	"github.com/chzyer/logex"
 	"github.com/ethereum/go-ethereum/common"
@@ -33,6 +37,21 @@ const (
 	ProofFulfilled
 )
 
+const (
+	// DEFAULT_GAS_LIMIT is the prover gas units of the Reserved and Auction requests if not configured
+	DEFAULT_GAS_LIMIT = 1_000_000_000
+	// DEFAULT_PROVING_CYCLES_PER_SEC is the throughput assumed for the provers if Config.ProvingCyclesPerSec isn't set.
+	// It's a conservative estimate of one GPU prover including the recursion and the wrapping into a snark,
+	// a request faster than it is only accepted if the measured throughput is configured
+	DEFAULT_PROVING_CYCLES_PER_SEC = 1_000_000
+)
+
+// ErrInvalidStrategy and ErrInvalidDeadline are returned by Config.Init if the request parameters are inconsistent
+var (
+	ErrInvalidStrategy = logex.Define("invalid %v strategy: %v")
+	ErrInvalidDeadline = logex.Define("cycle_limit %v can't be proved in the %vs before the deadline")
+)
+
 type Config struct {
 	Rpc              string `json:"rpc"`
 	PrivateKey       string `json:"private_key"`
@@ -41,8 +60,29 @@ type Config struct {
 	Version          string `json:"version"`
 	CycleLimit       uint64 `json:"cycle_limit"`
 	Timeout          uint64 `json:"timeout"`
+	// ProvingCyclesPerSec is the throughput of the provers, the deadlines too short for CycleLimit are rejected.
+	// Defaults to DEFAULT_PROVING_CYCLES_PER_SEC
+	ProvingCyclesPerSec uint64 `json:"proving_cycles_per_sec"`
+	// CircuitsDir contains the verifying keys of the circuits, used to verify the proofs off-chain
+	CircuitsDir string `json:"circuits_dir"`
 
 	Strategy sp1_proto.FulfillmentStrategy `json:"strategy"`
+	// MaxPricePerPgu is the max price in wei per prover gas unit, required by Auction
+	MaxPricePerPgu string `json:"max_price_per_pgu"`
+	// MinAuctionPeriod is the seconds the auction lasts at least, it must end before the deadline
+	MinAuctionPeriod uint64 `json:"min_auction_period"`
+	// Whitelist restricts the provers which may bid in the auction, any prover may bid if empty
+	Whitelist []common.Address `json:"whitelist"`
+	// GasLimit is the prover gas units the request may use, defaults to DEFAULT_GAS_LIMIT for Reserved and Auction
+	GasLimit uint64 `json:"gas_limit"`
+	// Domain, Auctioneer, Executor and Verifier bind the request to a deployment of the network, they're omitted if empty
+	Domain     common.Hash    `json:"domain"`
+	Auctioneer common.Address `json:"auctioneer"`
+	Executor   common.Address `json:"executor"`
+	Verifier   common.Address `json:"verifier"`
+
+	// Observer receives the progress of the requests, defaults to observe.LogObserver
+	Observer observe.Observer `json:"-"`
 }
 
 func (c *Config) Init() error {
@@ -70,9 +110,75 @@ func (c *Config) Init() error {
 	if c.Timeout == 0 {
 		c.Timeout = 14400
 	}
+	if c.ProvingCyclesPerSec == 0 {
+		c.ProvingCyclesPerSec = DEFAULT_PROVING_CYCLES_PER_SEC
+	}
+	if c.CircuitsDir == "" {
+		c.CircuitsDir = os.Getenv("SP1_CIRCUITS_DIR")
+	}
+	if c.CircuitsDir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			c.CircuitsDir = filepath.Join(home, ".sp1", "circuits")
+		}
+	}
 	if c.Strategy == sp1_proto.FulfillmentStrategy_UnspecifiedFulfillmentStrategy {
 		c.Strategy = sp1_proto.FulfillmentStrategy_Hosted
 	}
+	if c.GasLimit == 0 && c.Strategy != sp1_proto.FulfillmentStrategy_Hosted {
+		c.GasLimit = DEFAULT_GAS_LIMIT
+	}
+	if err := c.validateStrategy(); err != nil {
+		return logex.Trace(err)
+	}
+	if err := c.validateDeadline(); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+// validateDeadline checks the cycle limit can be proved before the deadline, the proving starts after the auction.
+// The gas limit isn't compared with the cycle limit, the prover gas units aren't cycles.
+func (c *Config) validateDeadline() error {
+	provingSecs := c.Timeout
+	if c.Strategy == sp1_proto.FulfillmentStrategy_Auction {
+		provingSecs -= c.MinAuctionPeriod
+	}
+	if c.CycleLimit > provingSecs*c.ProvingCyclesPerSec {
+		return ErrInvalidDeadline.Format(c.CycleLimit, provingSecs)
+	}
+	return nil
+}
+
+// validateStrategy checks the parameters of the strategy against the deadline.
+// Hosted requests keep the auction fields empty, so their signed body stays the same on the networks which don't know them
+func (c *Config) validateStrategy() error {
+	auction := c.MinAuctionPeriod > 0 || len(c.Whitelist) > 0
+	switch c.Strategy {
+	case sp1_proto.FulfillmentStrategy_Hosted:
+		if auction || c.MaxPricePerPgu != "" || c.GasLimit > 0 {
+			return ErrInvalidStrategy.Format(c.Strategy, "the pricing and auction parameters are not used")
+		}
+		return nil
+	case sp1_proto.FulfillmentStrategy_Reserved:
+		if auction {
+			return ErrInvalidStrategy.Format(c.Strategy, "the reserved capacity is not auctioned")
+		}
+	case sp1_proto.FulfillmentStrategy_Auction:
+		if c.MaxPricePerPgu == "" {
+			return ErrInvalidStrategy.Format(c.Strategy, "max_price_per_pgu is required")
+		}
+		if c.MinAuctionPeriod >= c.Timeout {
+			return ErrInvalidStrategy.Format(c.Strategy, fmt.Sprintf("min_auction_period %vs must end before the deadline of %vs", c.MinAuctionPeriod, c.Timeout))
+		}
+	default:
+		return ErrInvalidStrategy.Format(c.Strategy, "unknown strategy")
+	}
+	if c.MaxPricePerPgu != "" {
+		price, ok := new(big.Int).SetString(c.MaxPricePerPgu, 10)
+		if !ok || price.Sign() <= 0 {
+			return ErrInvalidStrategy.Format(c.Strategy, fmt.Sprintf("invalid max_price_per_pgu %q", c.MaxPricePerPgu))
+		}
+	}
 	return nil
 }
 
@@ -81,6 +187,7 @@ type Client struct {
 	conn     sp1_proto.ProverNetworkClient
 	artifact sp1_proto.ArtifactStoreClient
 	auth     *EIP712Auth
+	events   *observe.Emitter
 }
 
 func NewClient(cfg *Config) (*Client, error) {
@@ -104,6 +211,7 @@ func NewClient(cfg *Config) (*Client, error) {
 		conn:     grpcClient,
 		artifact: artifact,
 		auth:     NewEIP712Auth(key),
+		events:   observe.NewEmitter(observe.SourceSp1, cfg.Observer),
 	}
 	return client, nil
 }
@@ -125,7 +233,7 @@ func dialGrpcConn(rpcEndpoint string) (*grpc.ClientConn, error) {
 	} else {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
-	conn, err := grpc.NewClient(fmt.Sprintf("%v:%v", rpcUrl.Host, port), opts...)
+	conn, err := grpc.NewClient(fmt.Sprintf("%v:%v", rpcUrl.Hostname(), port), opts...)
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
@@ -136,8 +244,8 @@ func (c *Client) Public() common.Address {
 	return crypto.PubkeyToAddress(c.auth.key.PublicKey)
 }
 
-func (c *Client) s3(method string, url string, body io.Reader) ([]byte, error) {
-	req, err := http.NewRequest(method, url, body)
+func (c *Client) s3(ctx context.Context, method string, url string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
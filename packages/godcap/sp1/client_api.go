@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/automata-network/dcap-sdk/packages/godcap/bincode"
+	"github.com/automata-network/dcap-sdk/packages/godcap/observe"
 	"github.com/automata-network/dcap-sdk/packages/godcap/sp1/sp1_proto"
 	"github.com/chzyer/logex"
 	"github.com/ethereum/go-ethereum/common"
@@ -72,7 +73,7 @@ func (c *Client) CreateArtifact(ctx context.Context, aty sp1_proto.ArtifactType,
 	if err != nil {
 		return "", logex.Trace(err)
 	}
-	resp, err := c.s3(http.MethodPut, rsp.ArtifactPresignedUrl, bytes.NewReader(content))
+	resp, err := c.s3(ctx, http.MethodPut, rsp.ArtifactPresignedUrl, bytes.NewReader(content))
 	if err != nil {
 		return "", logex.Trace(err)
 	}
@@ -82,44 +83,40 @@ func (c *Client) CreateArtifact(ctx context.Context, aty sp1_proto.ArtifactType,
 
 // Prove creates and submits a proof, then polls for the proof status.
 func (c *Client) Prove(ctx context.Context, programVkHash common.Hash, stdin *SP1Stdin) (*SP1ProofWithPublicValues, error) {
-	requestId, err := c.CreateProof(ctx, programVkHash, stdin, sp1_proto.ProofMode_Groth16)
+	return c.ProveWithMode(ctx, programVkHash, stdin, sp1_proto.ProofMode_Groth16)
+}
+
+// ProveWithMode creates and submits a proof of the mode, then polls for the proof status.
+func (c *Client) ProveWithMode(ctx context.Context, programVkHash common.Hash, stdin *SP1Stdin, mode sp1_proto.ProofMode) (*SP1ProofWithPublicValues, error) {
+	requestId, err := c.CreateProof(ctx, programVkHash, stdin, mode)
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
-	proof, err := c.PollProof(ctx, requestId, time.Duration(c.cfg.PollIntervalSecs)*time.Second)
+	proof, err := c.PollProof(ctx, requestId, c.PollInterval())
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
 	return proof, nil
 }
 
+// PollInterval returns the configured interval of polling the proof status
+func (c *Client) PollInterval() time.Duration {
+	return time.Duration(c.cfg.PollIntervalSecs) * time.Second
+}
+
 // CreateProof creates a proof and uploads the necessary files.
 func (c *Client) CreateProof(ctx context.Context, programVkHash common.Hash, stdin *SP1Stdin, mode sp1_proto.ProofMode) ([]byte, error) {
 	nonce, err := c.RpcGetNonce(ctx)
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
-	logex.Infof("account=%v, nonce: %v", c.Public(), nonce)
-
 	stdinUrl, err := c.CreateArtifact(ctx, sp1_proto.ArtifactType_Stdin, stdin.Bincode())
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
-	reqBody := &sp1_proto.RequestProofRequestBody{
-		Nonce:      nonce,
-		Version:    fmt.Sprintf("sp1-%v", c.cfg.Version),
-		VkHash:     programVkHash[:],
-		Mode:       mode,
-		Strategy:   c.cfg.Strategy,
-		StdinUri:   stdinUrl,
-		Deadline:   uint64(time.Now().Unix()) + c.cfg.Timeout,
-		CycleLimit: c.cfg.CycleLimit,
-	}
-	reqBodyMsg, err := proto.Marshal(reqBody)
-	if err != nil {
-		return nil, logex.Trace(err)
-	}
-	reqSig, err := EIP191SignHash(c.auth.key, reqBodyMsg)
+	c.events.Emit(&observe.Event{Kind: observe.EventInputUploaded, ID: stdinUrl})
+	reqBody := c.newRequestBody(nonce, programVkHash, stdinUrl, mode, time.Now())
+	reqSig, err := c.signBody(reqBody)
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
@@ -131,9 +128,62 @@ func (c *Client) CreateProof(ctx context.Context, programVkHash common.Hash, std
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
+	c.events.Emit(&observe.Event{Kind: observe.EventSessionCreated, ID: hex.EncodeToString(response.Body.RequestId)})
 	return response.Body.RequestId, nil
 }
 
+// newRequestBody builds the request of the configured strategy, the auction parameters are empty for Hosted
+func (c *Client) newRequestBody(nonce uint64, programVkHash common.Hash, stdinUrl string, mode sp1_proto.ProofMode, now time.Time) *sp1_proto.RequestProofRequestBody {
+	body := &sp1_proto.RequestProofRequestBody{
+		Nonce:          nonce,
+		Version:        fmt.Sprintf("sp1-%v", c.cfg.Version),
+		VkHash:         programVkHash[:],
+		Mode:           mode,
+		Strategy:       c.cfg.Strategy,
+		StdinUri:       stdinUrl,
+		Deadline:       uint64(now.Unix()) + c.cfg.Timeout,
+		CycleLimit:     c.cfg.CycleLimit,
+		GasLimit:       c.cfg.GasLimit,
+		MaxPricePerPgu: c.cfg.MaxPricePerPgu,
+	}
+	if c.cfg.MinAuctionPeriod > 0 {
+		body.MinAuctionPeriod = &c.cfg.MinAuctionPeriod
+	}
+	for _, prover := range c.cfg.Whitelist {
+		body.Whitelist = append(body.Whitelist, prover.Bytes())
+	}
+	// the empty fields aren't encoded, the networks which don't know them see the same body
+	if c.cfg.Domain != (common.Hash{}) {
+		body.Domain = c.cfg.Domain.Bytes()
+	}
+	for _, field := range []struct {
+		addr  common.Address
+		value *[]byte
+	}{
+		{c.cfg.Auctioneer, &body.Auctioneer},
+		{c.cfg.Executor, &body.Executor},
+		{c.cfg.Verifier, &body.Verifier},
+	} {
+		if field.addr != (common.Address{}) {
+			*field.value = field.addr.Bytes()
+		}
+	}
+	return body
+}
+
+// signBody signs the protobuf encoding of the request body
+func (c *Client) signBody(body proto.Message) ([]byte, error) {
+	msg, err := proto.Marshal(body)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	sig, err := EIP191SignHash(c.auth.key, msg)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return sig, nil
+}
+
 // RpcGetProofStatus retrieves the status of the proof with the given proof ID.
 func (c *Client) RpcGetProofStatus(ctx context.Context, requestId []byte) (*sp1_proto.GetProofRequestStatusResponse, error) {
 	res, err := c.conn.GetProofRequestStatus(ctx, &sp1_proto.GetProofRequestStatusRequest{RequestId: requestId})
@@ -153,21 +203,31 @@ type SP1ProofWithPublicValues struct {
 // Bytes serializes the proof with public values into bytes.
 func (p *SP1ProofWithPublicValues) Bytes() ([]byte, error) {
 	switch p.Proof.Type.Raw() {
-	case 3: // Groth16
+	case PROOF_TYPE_PLONK:
+		proof := p.Proof.Plonk
+		return encodeProofBytes(proof.PlonkVkeyHash, proof.EncodedProof)
+	case PROOF_TYPE_GROTH16:
 		proof := p.Proof.Groth16
-		bytes := make([]byte, 0, 4+len(proof.EncodedProof))
-		bytes = append(bytes, proof.Groth16VkeyHash[:4]...)
-		decodedProof, err := hex.DecodeString(string(proof.EncodedProof))
-		if err != nil {
-			return nil, logex.Trace(err)
-		}
-		bytes = append(bytes, decodedProof...)
-		return bytes, nil
+		return encodeProofBytes(proof.Groth16VkeyHash, proof.EncodedProof)
+	case PROOF_TYPE_COMPRESSED:
+		return p.Proof.Compressed, nil
 	default:
 		return nil, logex.NewErrorf("unsupported proof mode: %v", p.Proof.Type)
 	}
 }
 
+// encodeProofBytes prefixes the proof with the selector of the verifier, the first 4 bytes of its vkey hash
+func encodeProofBytes(vkeyHash bincode.Bytes32, encodedProof bincode.String) ([]byte, error) {
+	decodedProof, err := hex.DecodeString(string(encodedProof))
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	bytes := make([]byte, 0, 4+len(decodedProof))
+	bytes = append(bytes, vkeyHash[:4]...)
+	bytes = append(bytes, decodedProof...)
+	return bytes, nil
+}
+
 // New creates a new instance of SP1ProofWithPublicValues.
 func (p *SP1ProofWithPublicValues) New() bincode.FromBin {
 	return new(SP1ProofWithPublicValues)
@@ -181,7 +241,12 @@ func (p *SP1ProofWithPublicValues) String() string {
 // FromBin deserializes the proof with public values from bytes.
 func (p *SP1ProofWithPublicValues) FromBin(data []byte) ([]byte, error) {
 	var err error
-	data, err = p.Proof.FromBin(data)
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == PROOF_TYPE_COMPRESSED {
+		p.Proof.Type = PROOF_TYPE_COMPRESSED
+		p.Proof.Compressed, data, err = splitCompressedProof(data[4:])
+	} else {
+		data, err = p.Proof.FromBin(data)
+	}
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
@@ -199,9 +264,18 @@ func (p *SP1ProofWithPublicValues) FromBin(data []byte) ([]byte, error) {
 // SP1Proof represents a proof with its type and specific proof data.
 type SP1Proof struct {
 	Type    bincode.U32
+	Plonk   *PlonkBn254Proof
 	Groth16 *Groth16Bn254Proof
+	// Compressed is the bincode of the SP1ReduceProof, e.g. for the recursion in another guest
+	Compressed []byte
 }
 
+const (
+	PROOF_TYPE_COMPRESSED = 1
+	PROOF_TYPE_PLONK      = 2
+	PROOF_TYPE_GROTH16    = 3
+)
+
 // New creates a new instance of SP1Proof.
 func (p *SP1Proof) New() bincode.FromBin {
 	return new(SP1Proof)
@@ -209,9 +283,14 @@ func (p *SP1Proof) New() bincode.FromBin {
 
 // String returns a string representation of SP1Proof.
 func (p *SP1Proof) String() string {
-	if uint32(p.Type) == 3 {
+	switch uint32(p.Type) {
+	case PROOF_TYPE_PLONK:
+		return fmt.Sprintf("SP1Proof:Plonk(%v)", p.Plonk.String())
+	case PROOF_TYPE_GROTH16:
 		return fmt.Sprintf("SP1Proof:Groth16(%v)", p.Groth16.String())
-	} else {
+	case PROOF_TYPE_COMPRESSED:
+		return fmt.Sprintf("SP1Proof:Compressed(%v bytes)", len(p.Compressed))
+	default:
 		return "unknown SP1Proof"
 	}
 }
@@ -224,12 +303,20 @@ func (p *SP1Proof) FromBin(data []byte) ([]byte, error) {
 		return nil, logex.Trace(err)
 	}
 	switch p.Type.Raw() {
-	case 3:
+	case PROOF_TYPE_PLONK:
+		p.Plonk = p.Plonk.New().(*PlonkBn254Proof)
+		data, err = p.Plonk.FromBin(data)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+	case PROOF_TYPE_GROTH16:
 		p.Groth16 = p.Groth16.New().(*Groth16Bn254Proof)
 		data, err = p.Groth16.FromBin(data)
 		if err != nil {
 			return nil, logex.Trace(err)
 		}
+	case PROOF_TYPE_COMPRESSED:
+		return nil, logex.NewErrorf("compressed proof is decoded with its public values")
 	default:
 		return nil, bincode.ErrUnexpectEnum.Format(p, p.Type)
 	}
@@ -259,6 +346,29 @@ func (p *Groth16Bn254Proof) FromBin(data []byte) ([]byte, error) {
 	return bincode.UnmarshalFields(data, []bincode.FromBin{&p.PublicInputs[0], &p.PublicInputs[1], &p.EncodedProof, &p.RawProof, &p.Groth16VkeyHash})
 }
 
+// PlonkBn254Proof represents a Plonk proof with specific data.
+type PlonkBn254Proof struct {
+	PublicInputs  [2]bincode.String
+	EncodedProof  bincode.String
+	RawProof      bincode.String
+	PlonkVkeyHash bincode.Bytes32
+}
+
+// New creates a new instance of PlonkBn254Proof.
+func (p *PlonkBn254Proof) New() bincode.FromBin {
+	return new(PlonkBn254Proof)
+}
+
+// String returns a string representation of PlonkBn254Proof.
+func (p *PlonkBn254Proof) String() string {
+	return fmt.Sprintf("PlonkBn254Proof{public_inputs: %v, encoded_proof: %v, raw_proof: %v, plonk_vkey_hash: %v}", p.PublicInputs, p.EncodedProof, p.RawProof, p.PlonkVkeyHash)
+}
+
+// FromBin deserializes the Plonk proof from bytes.
+func (p *PlonkBn254Proof) FromBin(data []byte) ([]byte, error) {
+	return bincode.UnmarshalFields(data, []bincode.FromBin{&p.PublicInputs[0], &p.PublicInputs[1], &p.EncodedProof, &p.RawProof, &p.PlonkVkeyHash})
+}
+
 // Buffer represents a buffer with binary data.
 type Buffer struct {
 	Data bincode.Bytes
@@ -299,7 +409,8 @@ func (c *Client) PollProof(ctx context.Context, requestId []byte, interval time.
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	errRetryTime := 3
-	isClaimed := false
+	id := hex.EncodeToString(requestId)
+	lastState := ""
 	for {
 		select {
 		case <-ctx.Done():
@@ -314,33 +425,24 @@ func (c *Client) PollProof(ctx context.Context, requestId []byte, interval time.
 				errRetryTime--
 				continue
 			}
-			switch status.FulfillmentStatus {
-			case sp1_proto.FulfillmentStatus_Fulfilled:
-				if status.ProofUri == nil {
-					return nil, logex.NewErrorf("missing receipt: %v", status)
-				}
-				proofBytes, err := c.s3(http.MethodGet, *status.ProofUri, nil)
-				if err != nil {
-					return nil, logex.Trace(err)
-				}
-				res, err := bincode.Unmarshal[*SP1ProofWithPublicValues](proofBytes)
-				if err != nil {
-					return nil, logex.Trace(err)
-				}
-				return res, nil
-			case sp1_proto.FulfillmentStatus_Assigned:
-				if !isClaimed {
-					logex.Infof("Proof request assigned, requestId=%x", requestId)
-					isClaimed = true
-				}
-			case sp1_proto.FulfillmentStatus_Unfulfillable:
-				return nil, logex.NewErrorf(
-					"Proof generation failed: %v",
-					status,
-				)
-			default:
-				logex.Infof("requestId %x is running: %v", requestId, status)
+			state := fmt.Sprintf("%v/%v", status.FulfillmentStatus, status.ExecutionStatus)
+			if state != lastState {
+				lastState = state
+				c.events.Emit(&observe.Event{Kind: observe.EventStateChanged, ID: id, State: state})
+			}
+			if err := checkProofStatus(id, status, time.Now()); err != nil {
+				c.events.Emit(&observe.Event{Kind: observe.EventFailed, ID: id, State: state, Err: err})
+				return nil, logex.Trace(err)
+			}
+			if status.FulfillmentStatus != sp1_proto.FulfillmentStatus_Fulfilled {
+				continue
+			}
+			res, err := c.fetchProof(ctx, status)
+			if err != nil {
+				return nil, logex.Trace(err)
 			}
+			c.events.Emit(&observe.Event{Kind: observe.EventFulfilled, ID: id})
+			return res, nil
 		}
 	}
 }
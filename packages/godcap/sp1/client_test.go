@@ -0,0 +1,84 @@
+package sp1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1/sp1_proto"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestConfigStrategy(t *testing.T) {
+	defer test.New(t)
+
+	hosted := &Config{}
+	test.Nil(hosted.Init())
+	test.Equal(hosted.Strategy, sp1_proto.FulfillmentStrategy_Hosted)
+	test.Equal(hosted.GasLimit, uint64(0))
+
+	for _, cfg := range []*Config{
+		{MaxPricePerPgu: "1000"},
+		{Strategy: sp1_proto.FulfillmentStrategy_Reserved, Whitelist: []common.Address{{1}}},
+		{Strategy: sp1_proto.FulfillmentStrategy_Auction},
+		{Strategy: sp1_proto.FulfillmentStrategy_Auction, MaxPricePerPgu: "-1"},
+		{Strategy: sp1_proto.FulfillmentStrategy_Auction, MaxPricePerPgu: "1000", MinAuctionPeriod: 600, Timeout: 300},
+		{Strategy: sp1_proto.FulfillmentStrategy(10)},
+	} {
+		test.True(logex.Equal(cfg.Init(), ErrInvalidStrategy))
+	}
+
+	reserved := &Config{Strategy: sp1_proto.FulfillmentStrategy_Reserved}
+	test.Nil(reserved.Init())
+	test.Equal(reserved.GasLimit, uint64(DEFAULT_GAS_LIMIT))
+	// the gas limit is in prover gas units, it's not compared with the cycles
+	test.Nil((&Config{Strategy: sp1_proto.FulfillmentStrategy_Reserved, GasLimit: 1000}).Init())
+
+	for _, cfg := range []*Config{
+		{Timeout: 10, CycleLimit: 200_000_000},
+		{Strategy: sp1_proto.FulfillmentStrategy_Auction, MaxPricePerPgu: "1000", MinAuctionPeriod: 100, Timeout: 110, CycleLimit: 200_000_000},
+		// a minute isn't enough for the default cycle limit
+		{Timeout: 60},
+		{Strategy: sp1_proto.FulfillmentStrategy_Auction, MaxPricePerPgu: "1000", MinAuctionPeriod: 3500, Timeout: 3600, CycleLimit: 500_000_000},
+	} {
+		test.True(logex.Equal(cfg.Init(), ErrInvalidDeadline))
+	}
+	// the throughput measured on the network
+	fast := &Config{Timeout: 60, ProvingCyclesPerSec: 5_000_000}
+	test.Nil(fast.Init())
+	test.Equal(fast.CycleLimit, uint64(150_000_000))
+
+	auction := &Config{
+		Strategy:         sp1_proto.FulfillmentStrategy_Auction,
+		MaxPricePerPgu:   "200000000",
+		MinAuctionPeriod: 60,
+		Whitelist:        []common.Address{{1}, {2}},
+		Timeout:          3600,
+	}
+	test.Nil(auction.Init())
+	client := &Client{cfg: auction}
+	now := time.Unix(1_700_000_000, 0)
+	body := client.newRequestBody(1, common.Hash{3}, "stdin", sp1_proto.ProofMode_Groth16, now)
+	test.Equal(body.Deadline, uint64(now.Unix())+3600)
+	test.Equal(body.GasLimit, uint64(DEFAULT_GAS_LIMIT))
+	test.Equal(body.MaxPricePerPgu, "200000000")
+	test.Equal(*body.MinAuctionPeriod, uint64(60))
+	test.Equal(body.Whitelist, [][]byte{common.Address{1}.Bytes(), common.Address{2}.Bytes()})
+
+	body = (&Client{cfg: hosted}).newRequestBody(1, common.Hash{3}, "stdin", sp1_proto.ProofMode_Groth16, now)
+	test.Nil(body.MinAuctionPeriod)
+	test.Equal(body.MaxPricePerPgu, "")
+	test.Equal(len(body.Whitelist), 0)
+	test.Nil(body.Domain)
+	test.Nil(body.Verifier)
+
+	auction.Domain = common.Hash{4}
+	auction.Auctioneer = common.Address{5}
+	auction.Verifier = common.Address{6}
+	body = client.newRequestBody(1, common.Hash{3}, "stdin", sp1_proto.ProofMode_Groth16, now)
+	test.Equal(body.Domain, common.Hash{4}.Bytes())
+	test.Equal(body.Auctioneer, common.Address{5}.Bytes())
+	test.Nil(body.Executor)
+	test.Equal(body.Verifier, common.Address{6}.Bytes())
+}
@@ -0,0 +1,151 @@
+package sp1
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bincode"
+	"github.com/chzyer/logex"
+)
+
+var ErrUnsupportedCompressedProof = logex.Define("compressed proofs of sp1 %v are not supported")
+
+// REDUCE_PROOF_VERSION is the major version of sp1 whose SP1ReduceProof layout is decoded
+const REDUCE_PROOF_VERSION = "v4."
+
+const (
+	feltSize   = 4
+	extSize    = 4 * feltSize
+	digestSize = 8 * feltSize
+	// septicDigestSize is a point of the septic curve, two elements of the degree 7 extension
+	septicDigestSize = 2 * 7 * feltSize
+)
+
+// reduceProofReader walks the bincode of SP1ReduceProof<BabyBearPoseidon2> of sp1 v4, the values are skipped:
+//
+//	SP1ReduceProof { vk: StarkVerifyingKey, proof: ShardProof }
+//	StarkVerifyingKey { commit, pc_start, initial_global_cumulative_sum, chip_information: Vec<(String, Dom, Dimensions)>, chip_ordering }
+//	ShardProof { commitment: [main, permutation, quotient], opened_values: Vec<ChipOpenedValues>, opening_proof: TwoAdicFriPcsProof, chip_ordering, public_values }
+type reduceProofReader struct {
+	data []byte
+	err  error
+}
+
+func (r *reduceProofReader) skip(n uint64) {
+	if r.err != nil {
+		return
+	}
+	if uint64(len(r.data)) < n {
+		r.err = logex.NewErrorf("unexpected end of the reduce proof")
+		return
+	}
+	r.data = r.data[n:]
+}
+
+func (r *reduceProofReader) u64() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) < 8 {
+		r.err = logex.NewErrorf("unexpected end of the reduce proof")
+		return 0
+	}
+	n := binary.LittleEndian.Uint64(r.data)
+	r.data = r.data[8:]
+	return n
+}
+
+// vec reads the length of a Vec or a HashMap, and each item with fn
+func (r *reduceProofReader) vec(fn func()) {
+	n := r.u64()
+	// every item takes at least one byte, the length can't exceed the rest
+	if r.err == nil && n > uint64(len(r.data)) {
+		r.err = logex.NewErrorf("invalid length in the reduce proof: %v", n)
+	}
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		fn()
+	}
+}
+
+func (r *reduceProofReader) items(size uint64) func() {
+	return func() { r.skip(size) }
+}
+
+func (r *reduceProofReader) str() {
+	r.skip(r.u64())
+}
+
+func (r *reduceProofReader) chipOrdering() {
+	r.vec(func() {
+		r.str()
+		r.u64()
+	})
+}
+
+func (r *reduceProofReader) verifyingKey() {
+	r.skip(digestSize + feltSize + septicDigestSize)
+	r.vec(func() {
+		r.str()
+		// Dom { log_n: usize, shift }, Dimensions { width: usize, height: usize }
+		r.skip(8 + feltSize + 8 + 8)
+	})
+	r.chipOrdering()
+}
+
+func (r *reduceProofReader) shardProof() {
+	r.skip(3 * digestSize)
+	r.vec(func() {
+		// preprocessed, main and permutation { local: Vec<EF>, next: Vec<EF> }
+		for i := 0; i < 3*2; i++ {
+			r.vec(r.items(extSize))
+		}
+		// quotient
+		r.vec(func() { r.vec(r.items(extSize)) })
+		// global_cumulative_sum, local_cumulative_sum, log_degree
+		r.skip(septicDigestSize + extSize + 8)
+	})
+	// FriProof { commit_phase_commits, query_proofs: Vec<Vec<{ sibling_value, opening_proof }>>, final_poly, pow_witness }
+	r.vec(r.items(digestSize))
+	r.vec(func() {
+		r.vec(func() {
+			r.skip(extSize)
+			r.vec(r.items(digestSize))
+		})
+	})
+	r.skip(extSize + feltSize)
+	// query_openings: Vec<Vec<BatchOpening { opened_values: Vec<Vec<F>>, opening_proof }>>
+	r.vec(func() {
+		r.vec(func() {
+			r.vec(func() { r.vec(r.items(feltSize)) })
+			r.vec(r.items(digestSize))
+		})
+	})
+	r.chipOrdering()
+	r.vec(r.items(feltSize))
+}
+
+// splitCompressedProof splits the bincode of the SP1ReduceProof from the public values and the version which follow it.
+// Only the layout of REDUCE_PROOF_VERSION is known, the proofs of the other versions are rejected.
+func splitCompressedProof(data []byte) ([]byte, []byte, error) {
+	r := &reduceProofReader{data: data}
+	r.verifyingKey()
+	r.shardProof()
+	if r.err != nil {
+		return nil, nil, logex.Trace(r.err)
+	}
+	reduceProof, rest := data[:len(data)-len(r.data)], r.data
+
+	var publicValues SP1PublicValues
+	afterPublicValues, err := publicValues.FromBin(rest)
+	if err != nil {
+		return nil, nil, logex.Trace(err)
+	}
+	var version bincode.String
+	if _, err := version.FromBin(afterPublicValues); err != nil {
+		return nil, nil, logex.Trace(err)
+	}
+	if !strings.HasPrefix(string(version), REDUCE_PROOF_VERSION) {
+		return nil, nil, ErrUnsupportedCompressedProof.Format(version)
+	}
+	return reduceProof, rest, nil
+}
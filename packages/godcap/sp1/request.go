@@ -0,0 +1,159 @@
+package sp1
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bincode"
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1/sp1_proto"
+	"github.com/chzyer/logex"
+)
+
+// The requests which failed on the network are reported by PollProof and DownloadProof instead of waiting for the timeout
+var (
+	ErrRequestUnexecutable  = logex.Define("request %v is unexecutable: %v")
+	ErrRequestUnfulfillable = logex.Define("request %v is unfulfillable: %v")
+	ErrDeadlineExceeded     = logex.Define("request %v exceeded its deadline %v")
+	ErrRequestNotFulfilled  = logex.Define("request %v is not fulfilled: %v")
+	ErrRequestNotFound      = logex.Define("request %v not found")
+)
+
+// checkProofStatus returns the error of the request which will never be fulfilled
+func checkProofStatus(id string, status *sp1_proto.GetProofRequestStatusResponse, now time.Time) error {
+	if status.FulfillmentStatus == sp1_proto.FulfillmentStatus_Fulfilled {
+		return nil
+	}
+	if status.ExecutionStatus == sp1_proto.ExecutionStatus_Unexecutable {
+		return ErrRequestUnexecutable.Format(id, status.FulfillmentStatus)
+	}
+	if status.FulfillmentStatus == sp1_proto.FulfillmentStatus_Unfulfillable {
+		return ErrRequestUnfulfillable.Format(id, status.ExecutionStatus)
+	}
+	if status.Deadline > 0 && uint64(now.Unix()) > status.Deadline {
+		return ErrDeadlineExceeded.Format(id, time.Unix(int64(status.Deadline), 0).UTC())
+	}
+	return nil
+}
+
+// fetchProof downloads the proof of the fulfilled request
+func (c *Client) fetchProof(ctx context.Context, status *sp1_proto.GetProofRequestStatusResponse) (*SP1ProofWithPublicValues, error) {
+	if status.ProofUri == nil {
+		return nil, logex.NewErrorf("missing receipt: %v", status)
+	}
+	proofBytes, err := c.s3(ctx, http.MethodGet, *status.ProofUri, nil)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	res, err := bincode.Unmarshal[*SP1ProofWithPublicValues](proofBytes)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return res, nil
+}
+
+// DownloadProof downloads the proof of a fulfilled request by its id
+func (c *Client) DownloadProof(ctx context.Context, requestId []byte) (*SP1ProofWithPublicValues, error) {
+	status, err := c.RpcGetProofStatus(ctx, requestId)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	id := hex.EncodeToString(requestId)
+	if err := checkProofStatus(id, status, time.Now()); err != nil {
+		return nil, logex.Trace(err)
+	}
+	if status.FulfillmentStatus != sp1_proto.FulfillmentStatus_Fulfilled {
+		return nil, ErrRequestNotFulfilled.Format(id, status.FulfillmentStatus)
+	}
+	proof, err := c.fetchProof(ctx, status)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return proof, nil
+}
+
+// RequestDetails returns the details of the request
+func (c *Client) RequestDetails(ctx context.Context, requestId []byte) (*sp1_proto.ProofRequest, error) {
+	res, err := c.conn.GetProofRequestDetails(ctx, &sp1_proto.GetProofRequestDetailsRequest{RequestId: requestId})
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if res.Request == nil {
+		return nil, ErrRequestNotFound.Format(hex.EncodeToString(requestId))
+	}
+	return res.Request, nil
+}
+
+// ListRequests returns the requests matching the filter, the requester defaults to the client's address
+func (c *Client) ListRequests(ctx context.Context, filter *sp1_proto.GetFilteredProofRequestsRequest) ([]*sp1_proto.ProofRequest, error) {
+	if filter == nil {
+		filter = new(sp1_proto.GetFilteredProofRequestsRequest)
+	}
+	if filter.Requester == nil {
+		addr := c.Public()
+		filter.Requester = addr[:]
+	}
+	res, err := c.conn.GetFilteredProofRequests(ctx, filter)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return res.Requests, nil
+}
+
+// PendingRequests returns the requests of the client which are still waiting for a fulfiller or being proved
+func (c *Client) PendingRequests(ctx context.Context) ([]*sp1_proto.ProofRequest, error) {
+	var requests []*sp1_proto.ProofRequest
+	now := uint64(time.Now().Unix())
+	for _, status := range []sp1_proto.FulfillmentStatus{sp1_proto.FulfillmentStatus_Requested, sp1_proto.FulfillmentStatus_Assigned} {
+		list, err := c.ListRequests(ctx, &sp1_proto.GetFilteredProofRequestsRequest{
+			FulfillmentStatus: &status,
+			MinimumDeadline:   &now,
+		})
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		requests = append(requests, list...)
+	}
+	return requests, nil
+}
+
+// CancelRequest cancels the request which isn't assigned to a fulfiller yet.
+// An assigned request can't be cancelled, stop polling it and it expires at its deadline
+func (c *Client) CancelRequest(ctx context.Context, requestId []byte) error {
+	nonce, err := c.RpcGetNonce(ctx)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	body := &sp1_proto.CancelRequestRequestBody{
+		Nonce:     nonce,
+		RequestId: requestId,
+	}
+	sig, err := c.signBody(body)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if _, err := c.conn.CancelRequest(ctx, &sp1_proto.CancelRequestRequest{
+		Format:    sp1_proto.MessageFormat_Binary,
+		Signature: sig,
+		Body:      body,
+	}); err != nil {
+		return logex.Trace(err, "CancelRequest")
+	}
+	return nil
+}
+
+// Balance returns the credits of the client's account on the network, in wei
+func (c *Client) Balance(ctx context.Context) (*big.Int, error) {
+	addr := c.Public()
+	res, err := c.conn.GetBalance(ctx, &sp1_proto.GetBalanceRequest{Address: addr[:]})
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	balance, ok := new(big.Int).SetString(res.Amount, 10)
+	if !ok {
+		return nil, logex.NewErrorf("invalid balance: %q", res.Amount)
+	}
+	return balance, nil
+}
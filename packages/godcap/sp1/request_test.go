@@ -0,0 +1,103 @@
+package sp1
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1/sp1_proto"
+	"github.com/automata-network/dcap-sdk/packages/godcap/testutil"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRequestManagement(t *testing.T) {
+	defer test.New(t)
+	network := testutil.NewFakeProverNetwork(t)
+	vkeyHash := common.BytesToHash(crypto.Keccak256([]byte("vkey")))
+	network.Proof = testutil.SP1Groth16Proof(vkeyHash, []byte{0xab, 0xcd}, []byte("public values"), "v4.0.0")
+
+	key, err := crypto.GenerateKey()
+	test.Nil(err)
+	client, err := NewClient(&Config{Rpc: network.Rpc, PrivateKey: hex.EncodeToString(crypto.FromECDSA(key))})
+	test.Nil(err)
+	ctx := context.Background()
+	stdin := NewSP1StdinFromInput([]byte("input"))
+
+	balance, err := client.Balance(ctx)
+	test.Nil(err)
+	test.Equal(balance.String(), network.Balance)
+
+	// fulfilled after the transient errors
+	done, err := client.CreateProof(ctx, vkeyHash, stdin, sp1_proto.ProofMode_Groth16)
+	test.Nil(err)
+	request := network.Requests()[0]
+	test.Equal(network.Artifact(request.Body.StdinUri), stdin.Bincode())
+	network.Fail("GetProofRequestStatus", codes.Unavailable, 2)
+	proof, err := client.PollProof(ctx, done, time.Millisecond)
+	test.Nil(err)
+	test.Equal(string(proof.PublicValues.Buffer.Data), "public values")
+	proofBytes, err := proof.Bytes()
+	test.Nil(err)
+	test.Equal(proofBytes, append(vkeyHash[:4:4], 0xab, 0xcd))
+
+	downloaded, err := client.DownloadProof(ctx, done)
+	test.Nil(err)
+	test.Equal(downloaded.String(), proof.String())
+
+	network.Statuses = []testutil.SP1ProofStatus{{Fulfillment: sp1_proto.FulfillmentStatus_Requested, Execution: sp1_proto.ExecutionStatus_Unexecuted}}
+	queued, err := client.CreateProof(ctx, vkeyHash, stdin, sp1_proto.ProofMode_Groth16)
+	test.Nil(err)
+	network.Statuses = []testutil.SP1ProofStatus{{Fulfillment: sp1_proto.FulfillmentStatus_Assigned, Execution: sp1_proto.ExecutionStatus_Unexecutable}}
+	bad, err := client.CreateProof(ctx, vkeyHash, stdin, sp1_proto.ProofMode_Groth16)
+	test.Nil(err)
+
+	pending, err := client.PendingRequests(ctx)
+	test.Nil(err)
+	test.Equal(len(pending), 2)
+	test.Equal(pending[0].RequestId, queued)
+	test.Equal(pending[1].RequestId, bad)
+
+	_, err = client.DownloadProof(ctx, queued)
+	test.True(logex.Equal(err, ErrRequestNotFulfilled))
+	_, err = client.PollProof(ctx, bad, time.Millisecond)
+	test.True(logex.Equal(err, ErrRequestUnexecutable))
+
+	// only the requests waiting for a fulfiller can be cancelled
+	test.NotNil(client.CancelRequest(ctx, bad))
+	test.Nil(client.CancelRequest(ctx, queued))
+	_, err = client.PollProof(ctx, queued, time.Millisecond)
+	test.True(logex.Equal(err, ErrRequestUnfulfillable))
+
+	details, err := client.RequestDetails(ctx, queued)
+	test.Nil(err)
+	test.Equal(details.FulfillmentStatus, sp1_proto.FulfillmentStatus_Unfulfillable)
+	_, err = client.RequestDetails(ctx, []byte("unknown"))
+	test.True(logex.Equal(err, ErrRequestNotFound))
+
+	network.Statuses = []testutil.SP1ProofStatus{{Fulfillment: sp1_proto.FulfillmentStatus_Assigned, Execution: sp1_proto.ExecutionStatus_Unexecuted}}
+	stuck, err := client.CreateProof(ctx, vkeyHash, stdin, sp1_proto.ProofMode_Groth16)
+	test.Nil(err)
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = client.PollProof(timeout, stuck, time.Millisecond)
+	test.True(logex.Equal(err, context.DeadlineExceeded))
+}
+
+func TestCheckProofStatus(t *testing.T) {
+	defer test.New(t)
+	now := time.Now()
+	status := &sp1_proto.GetProofRequestStatusResponse{
+		FulfillmentStatus: sp1_proto.FulfillmentStatus_Assigned,
+		Deadline:          uint64(now.Unix()),
+	}
+	test.Nil(checkProofStatus("id", status, now))
+	test.True(logex.Equal(checkProofStatus("id", status, now.Add(time.Minute)), ErrDeadlineExceeded))
+
+	status.FulfillmentStatus = sp1_proto.FulfillmentStatus_Fulfilled
+	test.Nil(checkProofStatus("id", status, now.Add(time.Minute)))
+}
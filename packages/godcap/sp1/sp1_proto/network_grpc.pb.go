@@ -19,9 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ProverNetwork_GetNonce_FullMethodName              = "/network.ProverNetwork/GetNonce"
-	ProverNetwork_RequestProof_FullMethodName          = "/network.ProverNetwork/RequestProof"
-	ProverNetwork_GetProofRequestStatus_FullMethodName = "/network.ProverNetwork/GetProofRequestStatus"
+	ProverNetwork_GetNonce_FullMethodName                 = "/network.ProverNetwork/GetNonce"
+	ProverNetwork_RequestProof_FullMethodName             = "/network.ProverNetwork/RequestProof"
+	ProverNetwork_GetProofRequestStatus_FullMethodName    = "/network.ProverNetwork/GetProofRequestStatus"
+	ProverNetwork_GetProofRequestDetails_FullMethodName   = "/network.ProverNetwork/GetProofRequestDetails"
+	ProverNetwork_GetFilteredProofRequests_FullMethodName = "/network.ProverNetwork/GetFilteredProofRequests"
+	ProverNetwork_CancelRequest_FullMethodName            = "/network.ProverNetwork/CancelRequest"
+	ProverNetwork_GetBalance_FullMethodName               = "/network.ProverNetwork/GetBalance"
 )
 
 // ProverNetworkClient is the client API for ProverNetwork service.
@@ -31,6 +35,10 @@ type ProverNetworkClient interface {
 	GetNonce(ctx context.Context, in *GetNonceRequest, opts ...grpc.CallOption) (*GetNonceResponse, error)
 	RequestProof(ctx context.Context, in *RequestProofRequest, opts ...grpc.CallOption) (*RequestProofResponse, error)
 	GetProofRequestStatus(ctx context.Context, in *GetProofRequestStatusRequest, opts ...grpc.CallOption) (*GetProofRequestStatusResponse, error)
+	GetProofRequestDetails(ctx context.Context, in *GetProofRequestDetailsRequest, opts ...grpc.CallOption) (*GetProofRequestDetailsResponse, error)
+	GetFilteredProofRequests(ctx context.Context, in *GetFilteredProofRequestsRequest, opts ...grpc.CallOption) (*GetFilteredProofRequestsResponse, error)
+	CancelRequest(ctx context.Context, in *CancelRequestRequest, opts ...grpc.CallOption) (*CancelRequestResponse, error)
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
 }
 
 type proverNetworkClient struct {
@@ -71,6 +79,46 @@ func (c *proverNetworkClient) GetProofRequestStatus(ctx context.Context, in *Get
 	return out, nil
 }
 
+func (c *proverNetworkClient) GetProofRequestDetails(ctx context.Context, in *GetProofRequestDetailsRequest, opts ...grpc.CallOption) (*GetProofRequestDetailsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProofRequestDetailsResponse)
+	err := c.cc.Invoke(ctx, ProverNetwork_GetProofRequestDetails_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proverNetworkClient) GetFilteredProofRequests(ctx context.Context, in *GetFilteredProofRequestsRequest, opts ...grpc.CallOption) (*GetFilteredProofRequestsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFilteredProofRequestsResponse)
+	err := c.cc.Invoke(ctx, ProverNetwork_GetFilteredProofRequests_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proverNetworkClient) CancelRequest(ctx context.Context, in *CancelRequestRequest, opts ...grpc.CallOption) (*CancelRequestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelRequestResponse)
+	err := c.cc.Invoke(ctx, ProverNetwork_CancelRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *proverNetworkClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBalanceResponse)
+	err := c.cc.Invoke(ctx, ProverNetwork_GetBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProverNetworkServer is the server API for ProverNetwork service.
 // All implementations must embed UnimplementedProverNetworkServer
 // for forward compatibility.
@@ -78,6 +126,10 @@ type ProverNetworkServer interface {
 	GetNonce(context.Context, *GetNonceRequest) (*GetNonceResponse, error)
 	RequestProof(context.Context, *RequestProofRequest) (*RequestProofResponse, error)
 	GetProofRequestStatus(context.Context, *GetProofRequestStatusRequest) (*GetProofRequestStatusResponse, error)
+	GetProofRequestDetails(context.Context, *GetProofRequestDetailsRequest) (*GetProofRequestDetailsResponse, error)
+	GetFilteredProofRequests(context.Context, *GetFilteredProofRequestsRequest) (*GetFilteredProofRequestsResponse, error)
+	CancelRequest(context.Context, *CancelRequestRequest) (*CancelRequestResponse, error)
+	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
 	mustEmbedUnimplementedProverNetworkServer()
 }
 
@@ -97,6 +149,18 @@ func (UnimplementedProverNetworkServer) RequestProof(context.Context, *RequestPr
 func (UnimplementedProverNetworkServer) GetProofRequestStatus(context.Context, *GetProofRequestStatusRequest) (*GetProofRequestStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetProofRequestStatus not implemented")
 }
+func (UnimplementedProverNetworkServer) GetProofRequestDetails(context.Context, *GetProofRequestDetailsRequest) (*GetProofRequestDetailsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProofRequestDetails not implemented")
+}
+func (UnimplementedProverNetworkServer) GetFilteredProofRequests(context.Context, *GetFilteredProofRequestsRequest) (*GetFilteredProofRequestsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFilteredProofRequests not implemented")
+}
+func (UnimplementedProverNetworkServer) CancelRequest(context.Context, *CancelRequestRequest) (*CancelRequestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelRequest not implemented")
+}
+func (UnimplementedProverNetworkServer) GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
 func (UnimplementedProverNetworkServer) mustEmbedUnimplementedProverNetworkServer() {}
 func (UnimplementedProverNetworkServer) testEmbeddedByValue()                       {}
 
@@ -172,6 +236,78 @@ func _ProverNetwork_GetProofRequestStatus_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProverNetwork_GetProofRequestDetails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProofRequestDetailsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProverNetworkServer).GetProofRequestDetails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProverNetwork_GetProofRequestDetails_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProverNetworkServer).GetProofRequestDetails(ctx, req.(*GetProofRequestDetailsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProverNetwork_GetFilteredProofRequests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFilteredProofRequestsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProverNetworkServer).GetFilteredProofRequests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProverNetwork_GetFilteredProofRequests_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProverNetworkServer).GetFilteredProofRequests(ctx, req.(*GetFilteredProofRequestsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProverNetwork_CancelRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProverNetworkServer).CancelRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProverNetwork_CancelRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProverNetworkServer).CancelRequest(ctx, req.(*CancelRequestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProverNetwork_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProverNetworkServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProverNetwork_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProverNetworkServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ProverNetwork_ServiceDesc is the grpc.ServiceDesc for ProverNetwork service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +327,22 @@ var ProverNetwork_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetProofRequestStatus",
 			Handler:    _ProverNetwork_GetProofRequestStatus_Handler,
 		},
+		{
+			MethodName: "GetProofRequestDetails",
+			Handler:    _ProverNetwork_GetProofRequestDetails_Handler,
+		},
+		{
+			MethodName: "GetFilteredProofRequests",
+			Handler:    _ProverNetwork_GetFilteredProofRequests_Handler,
+		},
+		{
+			MethodName: "CancelRequest",
+			Handler:    _ProverNetwork_CancelRequest_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _ProverNetwork_GetBalance_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "network.proto",
@@ -0,0 +1,142 @@
+package sp1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/groth16"
+	"github.com/automata-network/dcap-sdk/packages/godcap/plonk"
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrSelectorMismatch     = logex.Define("verifier selector mismatch: want %x, got %x")
+	ErrVkeyMismatch         = logex.Define("program vkey mismatch: want %v, got %v")
+	ErrPublicValuesMismatch = logex.Define("public values mismatch: want %v, got %v")
+	ErrMissingVerifyingKey  = logex.Define("the %v verifying key of sp1 %v is missing")
+)
+
+// HashPublicValues is the public input committing to the public values, the sha256 truncated to 253 bits
+func HashPublicValues(publicValues []byte) *big.Int {
+	hash := sha256.Sum256(publicValues)
+	hash[0] &= 0x1f
+	return new(big.Int).SetBytes(hash[:])
+}
+
+// VerifierKeys are the verifying keys of the circuits of a sp1 version
+type VerifierKeys struct {
+	Version string
+	Groth16 *groth16.VerifyingKey
+	// Groth16Hash is the sha256 of groth16_vk.bin, its first 4 bytes are the selector of the on-chain proof
+	Groth16Hash common.Hash
+	// Plonk is nil if the plonk circuit is not available
+	Plonk *plonk.VerifyingKey
+	// PlonkHash is the sha256 of plonk_vk.bin, its first 4 bytes are the selector of the on-chain proof
+	PlonkHash common.Hash
+}
+
+// NewVerifierKeys parses the verifying keys in the format of the circuit artifacts, plonkVk can be nil
+func NewVerifierKeys(version string, groth16Vk []byte, plonkVk []byte) (*VerifierKeys, error) {
+	vk, err := groth16.ParseGnarkVerifyingKey(groth16Vk)
+	if err != nil {
+		return nil, logex.Trace(err, "groth16_vk.bin")
+	}
+	keys := &VerifierKeys{
+		Version:     version,
+		Groth16:     vk,
+		Groth16Hash: sha256.Sum256(groth16Vk),
+	}
+	if len(plonkVk) > 0 {
+		keys.Plonk, err = plonk.ParseGnarkVerifyingKey(plonkVk)
+		if err != nil {
+			return nil, logex.Trace(err, "plonk_vk.bin")
+		}
+		keys.PlonkHash = sha256.Sum256(plonkVk)
+	}
+	return keys, nil
+}
+
+// LoadVerifierKeys loads the keys from the circuits directory of sp1, e.g. ~/.sp1/circuits
+func LoadVerifierKeys(dir string, version string) (*VerifierKeys, error) {
+	groth16Vk, err := os.ReadFile(filepath.Join(dir, "groth16", version, "groth16_vk.bin"))
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	plonkVk, err := os.ReadFile(filepath.Join(dir, "plonk", version, "plonk_vk.bin"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, logex.Trace(err)
+	}
+	return NewVerifierKeys(version, groth16Vk, plonkVk)
+}
+
+// VerifyBytes verifies the proof in the on-chain format returned by SP1ProofWithPublicValues.Bytes
+func (k *VerifierKeys) VerifyBytes(vkey common.Hash, publicValues []byte, proof []byte) error {
+	if len(proof) < 4 {
+		return groth16.ErrInvalidProof.Trace(len(proof))
+	}
+	selector := proof[:4]
+	inputs := []*big.Int{vkey.Big(), HashPublicValues(publicValues)}
+	if k.PlonkHash != (common.Hash{}) && bytes.Equal(selector, k.PlonkHash[:4]) {
+		if k.Plonk == nil {
+			return ErrMissingVerifyingKey.Format("plonk", k.Version)
+		}
+		if err := k.Plonk.Verify(proof[4:], inputs); err != nil {
+			return logex.Trace(err)
+		}
+		return nil
+	}
+	if !bytes.Equal(selector, k.Groth16Hash[:4]) {
+		return ErrSelectorMismatch.Format(k.Groth16Hash[:4], selector)
+	}
+	if k.Groth16 == nil {
+		return ErrMissingVerifyingKey.Format("groth16", k.Version)
+	}
+	if err := k.Groth16.Verify(proof[4:], inputs); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+// Verify verifies the proof is generated by the program of the vkey
+func (k *VerifierKeys) Verify(proof *SP1ProofWithPublicValues, vkey common.Hash) error {
+	var publicInputs [2]string
+	switch proof.Proof.Type.Raw() {
+	case PROOF_TYPE_PLONK:
+		if k.PlonkHash == (common.Hash{}) {
+			return ErrMissingVerifyingKey.Format("plonk", k.Version)
+		}
+		if common.Hash(proof.Proof.Plonk.PlonkVkeyHash) != k.PlonkHash {
+			return ErrSelectorMismatch.Format(k.PlonkHash[:4], proof.Proof.Plonk.PlonkVkeyHash[:4])
+		}
+		publicInputs = [2]string{string(proof.Proof.Plonk.PublicInputs[0]), string(proof.Proof.Plonk.PublicInputs[1])}
+	case PROOF_TYPE_GROTH16:
+		if common.Hash(proof.Proof.Groth16.Groth16VkeyHash) != k.Groth16Hash {
+			return ErrSelectorMismatch.Format(k.Groth16Hash[:4], proof.Proof.Groth16.Groth16VkeyHash[:4])
+		}
+		publicInputs = [2]string{string(proof.Proof.Groth16.PublicInputs[0]), string(proof.Proof.Groth16.PublicInputs[1])}
+	default:
+		return logex.NewErrorf("unsupported proof mode: %v", proof.Proof.Type)
+	}
+
+	if got, ok := new(big.Int).SetString(publicInputs[0], 10); !ok || got.Cmp(vkey.Big()) != 0 {
+		return ErrVkeyMismatch.Format(vkey, publicInputs[0])
+	}
+	publicValues := []byte(proof.PublicValues.Buffer.Data)
+	want := HashPublicValues(publicValues)
+	if got, ok := new(big.Int).SetString(publicInputs[1], 10); !ok || got.Cmp(want) != 0 {
+		return ErrPublicValuesMismatch.Format(want, publicInputs[1])
+	}
+
+	proofBytes, err := proof.Bytes()
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if err := k.VerifyBytes(vkey, publicValues, proofBytes); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
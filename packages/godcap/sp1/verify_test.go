@@ -0,0 +1,135 @@
+package sp1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bincode"
+	"github.com/automata-network/dcap-sdk/packages/godcap/groth16"
+	"github.com/automata-network/dcap-sdk/packages/godcap/plonk"
+	"github.com/automata-network/dcap-sdk/packages/godcap/testutil"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// decodeTestProof decodes the fixture, which is serialized with the stdin of sp1 v3
+func decodeTestProof() (*SP1ProofWithPublicValues, error) {
+	data, err := hex.DecodeString(testProof)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	var proof SP1ProofWithPublicValues
+	var stdin SP1Stdin
+	_, err = bincode.UnmarshalFields(data, []bincode.FromBin{&proof.Proof, &stdin, &proof.PublicValues, &proof.Sp1Version})
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &proof, nil
+}
+
+func TestHashPublicValues(t *testing.T) {
+	defer test.New(t)
+	proof, err := decodeTestProof()
+	test.Nil(err)
+	test.Equal(HashPublicValues(proof.PublicValues.Buffer.Data).String(), string(proof.Proof.Groth16.PublicInputs[1]))
+}
+
+func TestVerifierKeys(t *testing.T) {
+	defer test.New(t)
+	proof, err := decodeTestProof()
+	test.Nil(err)
+	vkeyInt, ok := new(big.Int).SetString(string(proof.Proof.Groth16.PublicInputs[0]), 10)
+	test.True(ok)
+	vkey := common.BigToHash(vkeyInt)
+
+	vk, td, err := testutil.NewGroth16TestKey(2)
+	test.Nil(err)
+	keys := &VerifierKeys{
+		Version:     string(proof.Sp1Version),
+		Groth16:     vk,
+		Groth16Hash: common.Hash(proof.Proof.Groth16.Groth16VkeyHash),
+	}
+	test.True(logex.Equal(keys.Verify(proof, common.Hash{1}), ErrVkeyMismatch))
+
+	simulated, err := td.Prove([]*big.Int{vkeyInt, HashPublicValues(proof.PublicValues.Buffer.Data)})
+	test.Nil(err)
+	proof.Proof.Groth16.EncodedProof = bincode.String(hex.EncodeToString(simulated))
+	test.Nil(keys.Verify(proof, vkey))
+
+	proofBytes, err := proof.Bytes()
+	test.Nil(err)
+	test.Nil(keys.VerifyBytes(vkey, proof.PublicValues.Buffer.Data, proofBytes))
+	test.True(logex.Equal(keys.VerifyBytes(vkey, []byte("other"), proofBytes), groth16.ErrVerification))
+
+	keys.PlonkHash = common.Hash{0xaa}
+	copy(proofBytes, keys.PlonkHash[:4])
+	test.True(logex.Equal(keys.VerifyBytes(vkey, proof.PublicValues.Buffer.Data, proofBytes), ErrMissingVerifyingKey))
+	proofBytes[0] = 0
+	test.True(logex.Equal(keys.VerifyBytes(vkey, proof.PublicValues.Buffer.Data, proofBytes), ErrSelectorMismatch))
+
+	proof.PublicValues.Buffer.Data[0] ^= 1
+	test.True(logex.Equal(keys.Verify(proof, vkey), ErrPublicValuesMismatch))
+}
+
+func TestVerifierKeysPlonk(t *testing.T) {
+	defer test.New(t)
+	vk, td, err := testutil.NewPlonkTestKey(2, 1)
+	test.Nil(err)
+	plonkVk, err := testutil.MarshalPlonkVerifyingKey(vk, false)
+	test.Nil(err)
+	keys := &VerifierKeys{Version: "v4.0.0-rc.3", Plonk: vk, PlonkHash: sha256.Sum256(plonkVk)}
+
+	vkey := common.Hash{1}
+	publicValues := []byte("public values")
+	inputs := []*big.Int{vkey.Big(), HashPublicValues(publicValues)}
+	simulated, err := td.Prove(inputs)
+	test.Nil(err)
+	proof := &SP1ProofWithPublicValues{
+		Proof: SP1Proof{Type: PROOF_TYPE_PLONK, Plonk: &PlonkBn254Proof{
+			PublicInputs:  [2]bincode.String{bincode.String(inputs[0].String()), bincode.String(inputs[1].String())},
+			EncodedProof:  bincode.String(hex.EncodeToString(simulated)),
+			PlonkVkeyHash: bincode.Bytes32(keys.PlonkHash),
+		}},
+		PublicValues: SP1PublicValues{Buffer: Buffer{Data: publicValues}},
+	}
+	test.Nil(keys.Verify(proof, vkey))
+
+	proofBytes, err := proof.Bytes()
+	test.Nil(err)
+	test.Nil(keys.VerifyBytes(vkey, publicValues, proofBytes))
+	test.True(logex.Equal(keys.VerifyBytes(vkey, []byte("other"), proofBytes), plonk.ErrVerification))
+	test.True(logex.Equal(keys.VerifyBytes(common.Hash{2}, publicValues, proofBytes), plonk.ErrVerification))
+
+	keys.PlonkHash = common.Hash{}
+	test.True(logex.Equal(keys.Verify(proof, vkey), ErrMissingVerifyingKey))
+}
+
+// TestVerifyTestProof verifies the fixture with the groth16_vk.bin of its sp1 version,
+// which is downloaded by the sp1 sdk to SP1_CIRCUITS_DIR or ~/.sp1/circuits
+func TestVerifyTestProof(t *testing.T) {
+	defer test.New(t)
+	proof, err := decodeTestProof()
+	test.Nil(err)
+	dir := os.Getenv("SP1_CIRCUITS_DIR")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		test.Nil(err)
+		dir = filepath.Join(home, ".sp1", "circuits")
+	}
+	version := string(proof.Sp1Version)
+	if _, err := os.Stat(filepath.Join(dir, "groth16", version, "groth16_vk.bin")); err != nil {
+		t.Skipf("groth16 circuit %v not found in %v", version, dir)
+	}
+	keys, err := LoadVerifierKeys(dir, version)
+	test.Nil(err)
+	test.Equal(keys.Groth16Hash, common.Hash(proof.Proof.Groth16.Groth16VkeyHash))
+
+	vkeyInt, ok := new(big.Int).SetString(string(proof.Proof.Groth16.PublicInputs[0]), 10)
+	test.True(ok)
+	test.Nil(keys.Verify(proof, common.BigToHash(vkeyInt)))
+}
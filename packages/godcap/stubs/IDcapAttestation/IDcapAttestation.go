@@ -31,7 +31,7 @@ var (
 
 // IDcapAttestationMetaData contains all meta data concerning the IDcapAttestation contract.
 var IDcapAttestationMetaData = &bind.MetaData{
-	ABI: "[{\"type\":\"function\",\"name\":\"getBp\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint16\",\"internalType\":\"uint16\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"verifyAndAttestOnChain\",\"inputs\":[{\"name\":\"rawQuote\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"success\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"output\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"stateMutability\":\"payable\"},{\"type\":\"function\",\"name\":\"verifyAndAttestWithZKProof\",\"inputs\":[{\"name\":\"output\",\"type\":\"bytes\",\"internalType\":\"bytes\"},{\"name\":\"zkCoprocessor\",\"type\":\"uint8\",\"internalType\":\"enumIDcapAttestation.ZkCoProcessorType\"},{\"name\":\"proofBytes\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"success\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"verifiedOutput\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"stateMutability\":\"payable\"}]",
+	ABI: "[{\"type\":\"function\",\"name\":\"getBp\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint16\",\"internalType\":\"uint16\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"programIdentifier\",\"inputs\":[{\"name\":\"zkCoProcessorType\",\"type\":\"uint8\",\"internalType\":\"uint8\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"programIdentifiers\",\"inputs\":[{\"name\":\"zkCoProcessorType\",\"type\":\"uint8\",\"internalType\":\"uint8\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32[]\",\"internalType\":\"bytes32[]\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"verifyAndAttestOnChain\",\"inputs\":[{\"name\":\"rawQuote\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"success\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"output\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"stateMutability\":\"payable\"},{\"type\":\"function\",\"name\":\"verifyAndAttestWithZKProof\",\"inputs\":[{\"name\":\"output\",\"type\":\"bytes\",\"internalType\":\"bytes\"},{\"name\":\"zkCoprocessor\",\"type\":\"uint8\",\"internalType\":\"enumIDcapAttestation.ZkCoProcessorType\"},{\"name\":\"proofBytes\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"success\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"verifiedOutput\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"stateMutability\":\"payable\"}]",
 }
 
 // IDcapAttestationABI is the input ABI used to generate the binding from.
@@ -211,6 +211,68 @@ func (_IDcapAttestation *IDcapAttestationCallerSession) GetBp() (uint16, error)
 	return _IDcapAttestation.Contract.GetBp(&_IDcapAttestation.CallOpts)
 }
 
+// ProgramIdentifier is a free data retrieval call binding the contract method 0x3043d2b1.
+//
+// Solidity: function programIdentifier(uint8 zkCoProcessorType) view returns(bytes32)
+func (_IDcapAttestation *IDcapAttestationCaller) ProgramIdentifier(opts *bind.CallOpts, zkCoProcessorType uint8) ([32]byte, error) {
+	var out []interface{}
+	err := _IDcapAttestation.contract.Call(opts, &out, "programIdentifier", zkCoProcessorType)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// ProgramIdentifier is a free data retrieval call binding the contract method 0x3043d2b1.
+//
+// Solidity: function programIdentifier(uint8 zkCoProcessorType) view returns(bytes32)
+func (_IDcapAttestation *IDcapAttestationSession) ProgramIdentifier(zkCoProcessorType uint8) ([32]byte, error) {
+	return _IDcapAttestation.Contract.ProgramIdentifier(&_IDcapAttestation.CallOpts, zkCoProcessorType)
+}
+
+// ProgramIdentifier is a free data retrieval call binding the contract method 0x3043d2b1.
+//
+// Solidity: function programIdentifier(uint8 zkCoProcessorType) view returns(bytes32)
+func (_IDcapAttestation *IDcapAttestationCallerSession) ProgramIdentifier(zkCoProcessorType uint8) ([32]byte, error) {
+	return _IDcapAttestation.Contract.ProgramIdentifier(&_IDcapAttestation.CallOpts, zkCoProcessorType)
+}
+
+// ProgramIdentifiers is a free data retrieval call binding the contract method 0xa01859a2.
+//
+// Solidity: function programIdentifiers(uint8 zkCoProcessorType) view returns(bytes32[])
+func (_IDcapAttestation *IDcapAttestationCaller) ProgramIdentifiers(opts *bind.CallOpts, zkCoProcessorType uint8) ([][32]byte, error) {
+	var out []interface{}
+	err := _IDcapAttestation.contract.Call(opts, &out, "programIdentifiers", zkCoProcessorType)
+
+	if err != nil {
+		return *new([][32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([][32]byte)).(*[][32]byte)
+
+	return out0, err
+
+}
+
+// ProgramIdentifiers is a free data retrieval call binding the contract method 0xa01859a2.
+//
+// Solidity: function programIdentifiers(uint8 zkCoProcessorType) view returns(bytes32[])
+func (_IDcapAttestation *IDcapAttestationSession) ProgramIdentifiers(zkCoProcessorType uint8) ([][32]byte, error) {
+	return _IDcapAttestation.Contract.ProgramIdentifiers(&_IDcapAttestation.CallOpts, zkCoProcessorType)
+}
+
+// ProgramIdentifiers is a free data retrieval call binding the contract method 0xa01859a2.
+//
+// Solidity: function programIdentifiers(uint8 zkCoProcessorType) view returns(bytes32[])
+func (_IDcapAttestation *IDcapAttestationCallerSession) ProgramIdentifiers(zkCoProcessorType uint8) ([][32]byte, error) {
+	return _IDcapAttestation.Contract.ProgramIdentifiers(&_IDcapAttestation.CallOpts, zkCoProcessorType)
+}
+
 // VerifyAndAttestOnChain is a paid mutator transaction binding the contract method 0x38d8480a.
 //
 // Solidity: function verifyAndAttestOnChain(bytes rawQuote) payable returns(bool success, bytes output)
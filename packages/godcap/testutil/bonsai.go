@@ -0,0 +1,275 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// FakeBonsai is an in-process Bonsai api. The sessions and the snarks go through the scripted statuses,
+// one per status query, and serve the receipt fixtures of the bonsai package once SUCCEEDED
+type FakeBonsai struct {
+	*httptest.Server
+	ApiKey string
+	// SessionStates are the statuses of the new sessions, defaults to SUCCEEDED
+	SessionStates []string
+	// SnarkStates are the statuses of the new snarks, defaults to SUCCEEDED
+	SnarkStates []string
+	// ErrorMsg is reported by the sessions and the snarks which end up neither RUNNING nor SUCCEEDED
+	ErrorMsg string
+	// Logs are the logs of every session
+	Logs string
+	// SessionReceipt is served for the succeeded sessions, defaults to test_receipt_1.hex
+	SessionReceipt []byte
+	// SnarkReceipt is served for the succeeded snarks, defaults to test_receipt_2.hex
+	SnarkReceipt []byte
+
+	mu       sync.Mutex
+	inputs   map[string][]byte
+	images   map[string][]byte
+	sessions map[string]*FakeBonsaiJob
+	snarks   map[string]*FakeBonsaiJob
+	order    []string
+	faults   []*fault
+}
+
+// FakeBonsaiJob is a session or a snark created on the FakeBonsai
+type FakeBonsaiJob struct {
+	ID string
+	// Request is the json body of the creation
+	Request json.RawMessage
+	// Stopped is set if the session is stopped
+	Stopped bool
+
+	states []string
+	polls  int
+	status string
+}
+
+// NewFakeBonsai starts the fake, it's closed with the test
+func NewFakeBonsai(t testing.TB) *FakeBonsai {
+	sessionReceipt, err := ReadBonsaiReceipt("test_receipt_1.hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snarkReceipt, err := ReadBonsaiReceipt("test_receipt_2.hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &FakeBonsai{
+		ApiKey:         "key",
+		SessionReceipt: sessionReceipt,
+		SnarkReceipt:   snarkReceipt,
+		inputs:         make(map[string][]byte),
+		images:         make(map[string][]byte),
+		sessions:       make(map[string]*FakeBonsaiJob),
+		snarks:         make(map[string]*FakeBonsaiJob),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// Fail makes the next n requests of the path prefix fail with the status code
+func (f *FakeBonsai) Fail(pathPrefix string, statusCode int, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = append(f.faults, &fault{key: pathPrefix, code: statusCode, left: n})
+}
+
+// Sessions returns the sessions in the creation order
+func (f *FakeBonsai) Sessions() []*FakeBonsaiJob {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sessions := make([]*FakeBonsaiJob, 0, len(f.order))
+	for _, id := range f.order {
+		sessions = append(sessions, f.sessions[id])
+	}
+	return sessions
+}
+
+// Input returns the uploaded input
+func (f *FakeBonsai) Input(uuid string) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.inputs[uuid]
+}
+
+// Image returns the uploaded elf of the image
+func (f *FakeBonsai) Image(imageId string) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.images[imageId]
+}
+
+type fakeBonsaiStatus struct {
+	Status     string           `json:"status"`
+	ReceiptURL string           `json:"receipt_url,omitempty"`
+	Output     string           `json:"output,omitempty"`
+	ErrorMsg   *string          `json:"error_msg,omitempty"`
+	State      string           `json:"state,omitempty"`
+	Stats      *fakeBonsaiStats `json:"stats,omitempty"`
+}
+
+type fakeBonsaiStats struct {
+	Segments    int    `json:"segments"`
+	TotalCycles uint64 `json:"total_cycles"`
+	Cycles      uint64 `json:"cycles"`
+}
+
+func (f *FakeBonsai) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := r.URL.Path
+	if code, ok := takeFault(f.faults, func(key string) bool { return strings.HasPrefix(path, key) }); ok {
+		replyBonsaiError(w, code, "InjectedFault", http.StatusText(code))
+		return
+	}
+	if !strings.HasPrefix(path, "/s3/") && r.Header.Get("x-api-key") != f.ApiKey {
+		replyBonsaiError(w, http.StatusUnauthorized, "Unauthorized", "invalid api key")
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		replyBonsaiError(w, http.StatusBadRequest, "InvalidBody", err.Error())
+		return
+	}
+
+	switch {
+	case path == "/inputs/upload":
+		uuid := fmt.Sprintf("input-%v", len(f.inputs)+1)
+		f.inputs[uuid] = nil
+		replyJSON(w, map[string]string{"url": f.URL + "/s3/inputs/" + uuid, "uuid": uuid})
+	case strings.HasPrefix(path, "/s3/inputs/"):
+		f.inputs[strings.TrimPrefix(path, "/s3/inputs/")] = body
+	case strings.HasPrefix(path, "/images/upload/"):
+		imageId := strings.TrimPrefix(path, "/images/upload/")
+		if _, ok := f.images[imageId]; ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		replyJSON(w, map[string]string{"url": f.URL + "/s3/images/" + imageId, "uuid": imageId})
+	case strings.HasPrefix(path, "/s3/images/"):
+		f.images[strings.TrimPrefix(path, "/s3/images/")] = body
+	case path == "/sessions/create":
+		var req struct {
+			Input string `json:"input"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			replyBonsaiError(w, http.StatusBadRequest, "InvalidBody", err.Error())
+			return
+		}
+		if _, ok := f.inputs[req.Input]; !ok {
+			replyBonsaiError(w, http.StatusNotFound, "InputNotFound", req.Input)
+			return
+		}
+		id := fmt.Sprintf("session-%v", len(f.sessions)+1)
+		f.sessions[id] = newFakeBonsaiJob(id, body, f.SessionStates)
+		f.order = append(f.order, id)
+		replyJSON(w, map[string]string{"uuid": id})
+	case strings.HasPrefix(path, "/sessions/status/"):
+		session, ok := f.sessions[strings.TrimPrefix(path, "/sessions/status/")]
+		if !ok {
+			replyBonsaiError(w, http.StatusNotFound, "SessionNotFound", path)
+			return
+		}
+		status := f.jobStatus(session)
+		if status.Status == "SUCCEEDED" {
+			status.ReceiptURL = f.URL + "/s3/receipts/" + session.ID
+			status.Stats = &fakeBonsaiStats{Segments: 1, TotalCycles: 1 << 20, Cycles: 1 << 19}
+		}
+		replyJSON(w, status)
+	case strings.HasPrefix(path, "/sessions/stop/"):
+		session, ok := f.sessions[strings.TrimPrefix(path, "/sessions/stop/")]
+		if !ok {
+			replyBonsaiError(w, http.StatusNotFound, "SessionNotFound", path)
+			return
+		}
+		session.Stopped = true
+		replyJSON(w, map[string]string{})
+	case strings.HasPrefix(path, "/sessions/logs/"):
+		w.Write([]byte(f.Logs))
+	case strings.HasPrefix(path, "/receipts/"):
+		session, ok := f.sessions[strings.TrimPrefix(path, "/receipts/")]
+		if !ok || session.status != "SUCCEEDED" {
+			replyBonsaiError(w, http.StatusNotFound, "ReceiptNotFound", path)
+			return
+		}
+		replyJSON(w, map[string]string{"url": f.URL + "/s3/receipts/" + session.ID})
+	case strings.HasPrefix(path, "/s3/receipts/"):
+		w.Write(f.SessionReceipt)
+	case path == "/snark/create":
+		var req struct {
+			SessionID string `json:"session_id"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			replyBonsaiError(w, http.StatusBadRequest, "InvalidBody", err.Error())
+			return
+		}
+		if session, ok := f.sessions[req.SessionID]; !ok || session.status != "SUCCEEDED" {
+			replyBonsaiError(w, http.StatusBadRequest, "SessionNotSucceeded", req.SessionID)
+			return
+		}
+		id := fmt.Sprintf("snark-%v", len(f.snarks)+1)
+		f.snarks[id] = newFakeBonsaiJob(id, body, f.SnarkStates)
+		replyJSON(w, map[string]string{"uuid": id})
+	case strings.HasPrefix(path, "/snark/status/"):
+		snark, ok := f.snarks[strings.TrimPrefix(path, "/snark/status/")]
+		if !ok {
+			replyBonsaiError(w, http.StatusNotFound, "SnarkNotFound", path)
+			return
+		}
+		status := f.jobStatus(snark)
+		if status.Status == "SUCCEEDED" {
+			status.Output = f.URL + "/s3/snarks/" + snark.ID
+		}
+		replyJSON(w, status)
+	case strings.HasPrefix(path, "/s3/snarks/"):
+		w.Write(f.SnarkReceipt)
+	case path == "/version":
+		replyJSON(w, map[string][]string{"risc0_zkvm": {r.Header.Get("x-risc0-version")}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeBonsaiJob(id string, request []byte, states []string) *FakeBonsaiJob {
+	if len(states) == 0 {
+		states = []string{"SUCCEEDED"}
+	}
+	return &FakeBonsaiJob{ID: id, Request: request, states: states}
+}
+
+// jobStatus advances the job to the next scripted status
+func (f *FakeBonsai) jobStatus(job *FakeBonsaiJob) *fakeBonsaiStatus {
+	if job.Stopped {
+		job.status = "ABORTED"
+	} else {
+		job.status = nextState(job.states, &job.polls)
+	}
+	status := &fakeBonsaiStatus{Status: job.status}
+	switch job.status {
+	case "RUNNING":
+		status.State = "Executor"
+	case "SUCCEEDED":
+	default:
+		status.ErrorMsg = &f.ErrorMsg
+	}
+	return status
+}
+
+func replyJSON(w http.ResponseWriter, val interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(val)
+}
+
+func replyBonsaiError(w http.ResponseWriter, statusCode int, errorCode string, errorMsg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{"error_code": errorCode, "error_msg": errorMsg})
+}
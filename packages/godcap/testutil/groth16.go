@@ -0,0 +1,87 @@
+package testutil
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/groth16"
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/crypto/bn256"
+)
+
+// Groth16Trapdoor is the secret of a verifying key created by NewGroth16TestKey.
+// Anyone holding it can simulate proofs of any inputs.
+type Groth16Trapdoor struct {
+	alpha, beta, gamma, delta *big.Int
+	ic                        []*big.Int
+}
+
+// NewGroth16TestKey creates a random verifying key of numInputs public inputs and its trapdoor
+func NewGroth16TestKey(numInputs int) (*groth16.VerifyingKey, *Groth16Trapdoor, error) {
+	td := new(Groth16Trapdoor)
+	scalars := []**big.Int{&td.alpha, &td.beta, &td.gamma, &td.delta}
+	for _, s := range scalars {
+		k, err := randScalar()
+		if err != nil {
+			return nil, nil, logex.Trace(err)
+		}
+		*s = k
+	}
+	vk := &groth16.VerifyingKey{
+		Alpha: new(bn256.G1).ScalarBaseMult(td.alpha),
+		Beta:  new(bn256.G2).ScalarBaseMult(td.beta),
+		Gamma: new(bn256.G2).ScalarBaseMult(td.gamma),
+		Delta: new(bn256.G2).ScalarBaseMult(td.delta),
+	}
+	for i := 0; i <= numInputs; i++ {
+		k, err := randScalar()
+		if err != nil {
+			return nil, nil, logex.Trace(err)
+		}
+		td.ic = append(td.ic, k)
+		vk.IC = append(vk.IC, new(bn256.G1).ScalarBaseMult(k))
+	}
+	return vk, td, nil
+}
+
+// Prove simulates a proof of the inputs which is accepted by the verifying key of the trapdoor
+func (td *Groth16Trapdoor) Prove(inputs []*big.Int) ([]byte, error) {
+	if len(inputs)+1 != len(td.ic) {
+		return nil, logex.NewErrorf("public inputs mismatch: %v", len(inputs))
+	}
+	x := new(big.Int).Set(td.ic[0])
+	for i, input := range inputs {
+		x.Add(x, new(big.Int).Mul(input, td.ic[i+1]))
+	}
+	a, err := randScalar()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	b, err := randScalar()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	// c = (a*b - alpha*beta - x*gamma) / delta
+	c := new(big.Int).Mul(a, b)
+	c.Sub(c, new(big.Int).Mul(td.alpha, td.beta))
+	c.Sub(c, new(big.Int).Mul(x, td.gamma))
+	c.Mul(c, new(big.Int).ModInverse(td.delta, groth16.SCALAR_FIELD))
+	c.Mod(c, groth16.SCALAR_FIELD)
+
+	proof := new(bn256.G1).ScalarBaseMult(a).Marshal()
+	proof = append(proof, new(bn256.G2).ScalarBaseMult(b).Marshal()...)
+	proof = append(proof, new(bn256.G1).ScalarBaseMult(c).Marshal()...)
+	return proof, nil
+}
+
+func randScalar() (*big.Int, error) {
+	for {
+		k, err := rand.Int(rand.Reader, groth16.SCALAR_FIELD)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package testutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/plonk"
+	"github.com/chzyer/logex"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+)
+
+// PlonkTrapdoor is the toxic waste of the kzg setup of a verifying key created by NewPlonkTestKey.
+// Anyone holding it can open any commitment to any value.
+type PlonkTrapdoor struct {
+	vk  *plonk.VerifyingKey
+	tau fr.Element
+}
+
+// NewPlonkTestKey creates a random verifying key of numInputs public inputs and numCommitments custom gates,
+// and its trapdoor
+func NewPlonkTestKey(numInputs, numCommitments int) (*plonk.VerifyingKey, *PlonkTrapdoor, error) {
+	const size = 1 << 10
+	td := new(PlonkTrapdoor)
+	vk := &plonk.VerifyingKey{Size: size, NbPublicVariables: uint64(numInputs)}
+	vk.SizeInv.SetUint64(size).Inverse(&vk.SizeInv)
+	// ω generates the subgroup of the size
+	var domainSize big.Int
+	domainSize.Div(new(big.Int).Sub(fr.Modulus(), big.NewInt(1)), big.NewInt(size))
+	vk.Generator.SetUint64(5).Exp(vk.Generator, &domainSize)
+	vk.CosetShift.SetUint64(5)
+
+	points := []*bn254.G1Affine{&vk.S[0], &vk.S[1], &vk.S[2], &vk.Ql, &vk.Qr, &vk.Qm, &vk.Qo, &vk.Qk}
+	vk.Qcp = make([]bn254.G1Affine, numCommitments)
+	for i := range vk.Qcp {
+		points = append(points, &vk.Qcp[i])
+		vk.CommitmentConstraintIndexes = append(vk.CommitmentConstraintIndexes, uint64(i+1))
+	}
+	if err := randPoints(points...); err != nil {
+		return nil, nil, logex.Trace(err)
+	}
+	if _, err := td.tau.SetRandom(); err != nil {
+		return nil, nil, logex.Trace(err)
+	}
+	_, _, vk.Kzg.G1, vk.Kzg.G2[0] = bn254.Generators()
+	vk.Kzg.G2[1].ScalarMultiplication(&vk.Kzg.G2[0], td.tau.BigInt(new(big.Int)))
+	td.vk = vk
+	return vk, td, nil
+}
+
+// Prove simulates a proof of the inputs in the solidity encoding, the commitments are random
+// and the openings are forged with the trapdoor
+func (td *PlonkTrapdoor) Prove(inputs []*big.Int) ([]byte, error) {
+	vk := td.vk
+	if uint64(len(inputs)) != vk.NbPublicVariables {
+		return nil, logex.NewErrorf("public inputs mismatch: %v", len(inputs))
+	}
+	publicWitness := make([]fr.Element, len(inputs))
+	for i := range inputs {
+		publicWitness[i].SetBigInt(inputs[i])
+	}
+
+	proof := &plonk.Proof{Bsb22Commitments: make([]bn254.G1Affine, len(vk.Qcp))}
+	points := []*bn254.G1Affine{&proof.LRO[0], &proof.LRO[1], &proof.LRO[2], &proof.H[0], &proof.H[1], &proof.H[2], &proof.Z}
+	for i := range proof.Bsb22Commitments {
+		points = append(points, &proof.Bsb22Commitments[i])
+	}
+	if err := randPoints(points...); err != nil {
+		return nil, logex.Trace(err)
+	}
+	proof.BatchedProof.ClaimedValues = make([]fr.Element, 6+len(vk.Qcp))
+	for i := 1; i < len(proof.BatchedProof.ClaimedValues); i++ {
+		proof.BatchedProof.ClaimedValues[i].SetRandom()
+	}
+	proof.ZShiftedOpening.ClaimedValue.SetRandom()
+
+	c, err := vk.DeriveChallenges(proof, publicWitness)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	linearised, err := vk.Linearise(proof, publicWitness, c)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	zu := proof.ZShiftedOpening.ClaimedValue
+	folded, foldedDigest, err := kzg.FoldProof(vk.FoldedDigests(proof, linearised), &proof.BatchedProof, c.Zeta, sha256.New(), zu.Marshal())
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	proof.BatchedProof.H = td.open(foldedDigest, folded.ClaimedValue, c.Zeta)
+	var shiftedZeta fr.Element
+	shiftedZeta.Mul(&c.Zeta, &vk.Generator)
+	proof.ZShiftedOpening.H = td.open(proof.Z, zu, shiftedZeta)
+	return MarshalPlonkProof(proof), nil
+}
+
+// open returns the quotient [(d-v)/(τ-z)]G1 which opens the digest d to v at z
+func (td *PlonkTrapdoor) open(digest bn254.G1Affine, value, point fr.Element) bn254.G1Affine {
+	var quotient bn254.G1Affine
+	var valueG1 bn254.G1Affine
+	valueG1.ScalarMultiplication(&td.vk.Kzg.G1, value.BigInt(new(big.Int)))
+	quotient.Sub(&digest, &valueG1)
+	var inv fr.Element
+	inv.Sub(&td.tau, &point).Inverse(&inv)
+	quotient.ScalarMultiplication(&quotient, inv.BigInt(new(big.Int)))
+	return quotient
+}
+
+// MarshalPlonkProof encodes the proof in the solidity encoding, see plonk.VerifyingKey.ParseProof
+func MarshalPlonkProof(proof *plonk.Proof) []byte {
+	var buf bytes.Buffer
+	g1 := func(p *bn254.G1Affine) { buf.Write(p.Marshal()) }
+	for i := range proof.LRO {
+		g1(&proof.LRO[i])
+	}
+	for i := range proof.H {
+		g1(&proof.H[i])
+	}
+	for i := 1; i < 6; i++ {
+		buf.Write(proof.BatchedProof.ClaimedValues[i].Marshal())
+	}
+	g1(&proof.Z)
+	buf.Write(proof.ZShiftedOpening.ClaimedValue.Marshal())
+	g1(&proof.BatchedProof.H)
+	g1(&proof.ZShiftedOpening.H)
+	for i := 6; i < len(proof.BatchedProof.ClaimedValues); i++ {
+		buf.Write(proof.BatchedProof.ClaimedValues[i].Marshal())
+	}
+	for i := range proof.Bsb22Commitments {
+		g1(&proof.Bsb22Commitments[i])
+	}
+	return buf.Bytes()
+}
+
+// MarshalPlonkVerifyingKey encodes the verifying key like gnark, see plonk.ParseGnarkVerifyingKey.
+// lines are the precomputed kzg lines of the newer gnark versions, they are zero.
+func MarshalPlonkVerifyingKey(vk *plonk.VerifyingKey, lines bool) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := bn254.NewEncoder(&buf)
+	for _, v := range []interface{}{
+		&vk.Size, &vk.SizeInv, &vk.Generator, &vk.NbPublicVariables, &vk.CosetShift,
+		&vk.S[0], &vk.S[1], &vk.S[2], &vk.Ql, &vk.Qr, &vk.Qm, &vk.Qo, &vk.Qk, vk.Qcp,
+		&vk.Kzg.G1, &vk.Kzg.G2[0], &vk.Kzg.G2[1],
+	} {
+		if err := enc.Encode(v); err != nil {
+			return nil, logex.Trace(err)
+		}
+	}
+	if lines {
+		buf.Write(make([]byte, 2*2*66*4*32))
+	}
+	if err := enc.Encode(vk.CommitmentConstraintIndexes); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func randPoints(points ...*bn254.G1Affine) error {
+	_, _, g1, _ := bn254.Generators()
+	for _, p := range points {
+		var k fr.Element
+		if _, err := k.SetRandom(); err != nil {
+			return logex.Trace(err)
+		}
+		p.ScalarMultiplication(&g1, k.BigInt(new(big.Int)))
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bincode"
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1/sp1_proto"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// SP1_PROOF_TYPE_GROTH16 is the bincode enum of the Groth16 proofs, see sp1.PROOF_TYPE_GROTH16
+	SP1_PROOF_TYPE_GROTH16 = 3
+	// SP1_PROOF_TYPE_COMPRESSED is the bincode enum of the compressed proofs, see sp1.PROOF_TYPE_COMPRESSED
+	SP1_PROOF_TYPE_COMPRESSED = 1
+)
+
+// SP1ProofStatus is a scripted status of the requests on the FakeProverNetwork
+type SP1ProofStatus struct {
+	Fulfillment sp1_proto.FulfillmentStatus
+	Execution   sp1_proto.ExecutionStatus
+}
+
+// SP1_PROOF_FULFILLED is the default script of the requests, requested, then executed and fulfilled
+var SP1_PROOF_FULFILLED = []SP1ProofStatus{
+	{sp1_proto.FulfillmentStatus_Requested, sp1_proto.ExecutionStatus_Unexecuted},
+	{sp1_proto.FulfillmentStatus_Fulfilled, sp1_proto.ExecutionStatus_Executed},
+}
+
+// SP1Groth16Proof encodes the bincode of a sp1 Groth16 proof with public values
+func SP1Groth16Proof(vkeyHash common.Hash, encodedProof []byte, publicValues []byte, version string) []byte {
+	var buf []byte
+	buf = binary.LittleEndian.AppendUint32(buf, SP1_PROOF_TYPE_GROTH16)
+	for _, item := range []string{"0", "0", hex.EncodeToString(encodedProof), hex.EncodeToString(encodedProof)} {
+		buf = append(buf, bincode.Bytes(item).Bincode()...)
+	}
+	buf = append(buf, vkeyHash[:]...)
+	buf = append(buf, bincode.Bytes(publicValues).Bincode()...)
+	buf = append(buf, bincode.Bytes(version).Bincode()...)
+	return buf
+}
+
+// SP1ReduceProof encodes a SP1ReduceProof of sp1 v4 with one chip and one FRI query, the field elements are filled with fill
+func SP1ReduceProof(fill byte) []byte {
+	var buf []byte
+	felts := func(n int) { buf = append(buf, bytes.Repeat([]byte{fill}, 4*n)...) }
+	length := func(n int) { buf = binary.LittleEndian.AppendUint64(buf, uint64(n)) }
+	chipOrdering := func() {
+		length(1)
+		buf = append(buf, bincode.Bytes("Cpu").Bincode()...)
+		length(0)
+	}
+	// vk: commit, pc_start, initial_global_cumulative_sum, chip_information, chip_ordering
+	felts(8 + 1 + 14)
+	length(1)
+	buf = append(buf, bincode.Bytes("Cpu").Bincode()...)
+	length(10)
+	felts(1)
+	length(8)
+	length(1 << 10)
+	chipOrdering()
+	// proof: commitments, opened_values
+	felts(3 * 8)
+	length(1)
+	for i := 0; i < 3*2; i++ {
+		length(1)
+		felts(4)
+	}
+	length(1)
+	length(1)
+	felts(4)
+	felts(14 + 4)
+	length(10)
+	// fri proof
+	length(1)
+	felts(8)
+	length(1)
+	length(1)
+	felts(4)
+	length(1)
+	felts(8)
+	felts(4 + 1)
+	// query openings
+	length(1)
+	length(1)
+	length(1)
+	length(1)
+	felts(1)
+	length(1)
+	felts(8)
+	// chip_ordering, public_values
+	chipOrdering()
+	length(2)
+	felts(2)
+	return buf
+}
+
+// SP1CompressedProof encodes the bincode of a sp1 compressed proof with public values, see SP1ReduceProof
+func SP1CompressedProof(reduceProof []byte, publicValues []byte, version string) []byte {
+	buf := binary.LittleEndian.AppendUint32(nil, SP1_PROOF_TYPE_COMPRESSED)
+	buf = append(buf, reduceProof...)
+	buf = append(buf, bincode.Bytes(publicValues).Bincode()...)
+	buf = append(buf, bincode.Bytes(version).Bincode()...)
+	return buf
+}
+
+// FakeProverNetwork is an in-process sp1 prover network. It serves the ProverNetwork and the ArtifactStore
+// services over grpc and the artifacts over http, the requests go through the scripted Statuses, one per status query
+type FakeProverNetwork struct {
+	sp1_proto.UnimplementedProverNetworkServer
+	sp1_proto.UnimplementedArtifactStoreServer
+
+	// Rpc is the endpoint of sp1.Config.Rpc
+	Rpc string
+	// Statuses are the statuses of the new requests, defaults to SP1_PROOF_FULFILLED
+	Statuses []SP1ProofStatus
+	// Proof is served for the fulfilled requests, defaults to a Groth16 proof of empty public values
+	Proof []byte
+	// Balance is the credits of the accounts in wei
+	Balance string
+
+	server    *grpc.Server
+	http      *httptest.Server
+	mu        sync.Mutex
+	nonces    map[common.Address]uint64
+	artifacts map[string][]byte
+	requests  []*FakeProofRequest
+	faults    []*fault
+}
+
+// FakeProofRequest is a request created on the FakeProverNetwork
+type FakeProofRequest struct {
+	ID        []byte
+	Requester common.Address
+	Body      *sp1_proto.RequestProofRequestBody
+	Cancelled bool
+
+	statuses []SP1ProofStatus
+	polls    int
+	status   SP1ProofStatus
+}
+
+// NewFakeProverNetwork starts the fake, it's stopped with the test
+func NewFakeProverNetwork(t testing.TB) *FakeProverNetwork {
+	f := &FakeProverNetwork{
+		Proof:     SP1Groth16Proof(common.Hash{}, []byte{0}, nil, "v4.0.0-rc.3"),
+		Balance:   "1000000000000000000",
+		nonces:    make(map[common.Address]uint64),
+		artifacts: make(map[string][]byte),
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.server = grpc.NewServer(grpc.UnaryInterceptor(f.intercept))
+	sp1_proto.RegisterProverNetworkServer(f.server, f)
+	sp1_proto.RegisterArtifactStoreServer(f.server, f)
+	go f.server.Serve(listener)
+	f.http = httptest.NewServer(http.HandlerFunc(f.serveArtifact))
+	f.Rpc = "http://" + listener.Addr().String()
+	t.Cleanup(func() {
+		f.server.Stop()
+		f.http.Close()
+	})
+	return f
+}
+
+// Fail makes the next n calls of the method, e.g. GetProofRequestStatus, fail with the code
+func (f *FakeProverNetwork) Fail(method string, code codes.Code, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = append(f.faults, &fault{key: method, code: int(code), left: n})
+}
+
+// Requests returns the requests in the creation order
+func (f *FakeProverNetwork) Requests() []*FakeProofRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*FakeProofRequest(nil), f.requests...)
+}
+
+// Artifact returns the uploaded artifact of the uri
+func (f *FakeProverNetwork) Artifact(uri string) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.artifacts[uri]
+}
+
+func (f *FakeProverNetwork) intercept(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	f.mu.Lock()
+	code, ok := takeFault(f.faults, func(key string) bool { return strings.HasSuffix(info.FullMethod, "/"+key) })
+	f.mu.Unlock()
+	if ok {
+		return nil, status.Errorf(codes.Code(code), "injected fault")
+	}
+	return handler(ctx, req)
+}
+
+func (f *FakeProverNetwork) serveArtifact(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/artifacts/") && r.Method == http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.artifacts[artifactUri(strings.TrimPrefix(r.URL.Path, "/artifacts/"))] = body
+	case strings.HasPrefix(r.URL.Path, "/proofs/"):
+		w.Write(f.Proof)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func artifactUri(id string) string {
+	return "s3://sp1-artifacts/" + id
+}
+
+// signer recovers the account of the signed body
+func signer(body proto.Message, sig []byte) (common.Address, error) {
+	msg, err := proto.Marshal(body)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pubkey, err := crypto.SigToPub(accounts.TextHash(msg), sig)
+	if err != nil {
+		return common.Address{}, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// useNonce checks the nonce of the signed body against the account, the caller holds the lock
+func (f *FakeProverNetwork) useNonce(account common.Address, nonce uint64) error {
+	if f.nonces[account] != nonce {
+		return status.Errorf(codes.InvalidArgument, "invalid nonce %v, want %v", nonce, f.nonces[account])
+	}
+	f.nonces[account]++
+	return nil
+}
+
+func (f *FakeProverNetwork) request(id []byte) (*FakeProofRequest, error) {
+	for _, req := range f.requests {
+		if bytes.Equal(req.ID, id) {
+			return req, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "request %x not found", id)
+}
+
+func (f *FakeProverNetwork) CreateArtifact(ctx context.Context, req *sp1_proto.CreateArtifactRequest) (*sp1_proto.CreateArtifactResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := fmt.Sprintf("artifact-%v", len(f.artifacts)+1)
+	f.artifacts[artifactUri(id)] = nil
+	return &sp1_proto.CreateArtifactResponse{
+		ArtifactUri:          artifactUri(id),
+		ArtifactPresignedUrl: f.http.URL + "/artifacts/" + id,
+	}, nil
+}
+
+func (f *FakeProverNetwork) GetNonce(ctx context.Context, req *sp1_proto.GetNonceRequest) (*sp1_proto.GetNonceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &sp1_proto.GetNonceResponse{Nonce: f.nonces[common.BytesToAddress(req.Address)]}, nil
+}
+
+func (f *FakeProverNetwork) RequestProof(ctx context.Context, req *sp1_proto.RequestProofRequest) (*sp1_proto.RequestProofResponse, error) {
+	requester, err := signer(req.Body, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.artifacts[req.Body.StdinUri]; !ok {
+		return nil, status.Errorf(codes.NotFound, "stdin %v not found", req.Body.StdinUri)
+	}
+	if req.Body.Deadline <= uint64(time.Now().Unix()) {
+		return nil, status.Errorf(codes.InvalidArgument, "deadline %v has passed", req.Body.Deadline)
+	}
+	if err := f.useNonce(requester, req.Body.Nonce); err != nil {
+		return nil, err
+	}
+	statuses := f.Statuses
+	if len(statuses) == 0 {
+		statuses = SP1_PROOF_FULFILLED
+	}
+	request := &FakeProofRequest{
+		ID:        crypto.Keccak256(requester[:], binary.BigEndian.AppendUint64(nil, req.Body.Nonce)),
+		Requester: requester,
+		Body:      req.Body,
+		statuses:  statuses,
+		status:    statuses[0],
+	}
+	f.requests = append(f.requests, request)
+	return &sp1_proto.RequestProofResponse{
+		TxHash: crypto.Keccak256(request.ID),
+		Body:   &sp1_proto.RequestProofResponseBody{RequestId: request.ID},
+	}, nil
+}
+
+func (f *FakeProverNetwork) GetProofRequestStatus(ctx context.Context, req *sp1_proto.GetProofRequestStatusRequest) (*sp1_proto.GetProofRequestStatusResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	request, err := f.request(req.RequestId)
+	if err != nil {
+		return nil, err
+	}
+	if !request.Cancelled {
+		request.status = nextState(request.statuses, &request.polls)
+	}
+	res := &sp1_proto.GetProofRequestStatusResponse{
+		FulfillmentStatus: request.status.Fulfillment,
+		ExecutionStatus:   request.status.Execution,
+		RequestTxHash:     crypto.Keccak256(request.ID),
+		Deadline:          request.Body.Deadline,
+	}
+	if request.status.Fulfillment == sp1_proto.FulfillmentStatus_Fulfilled {
+		proofUri := fmt.Sprintf("%v/proofs/%x", f.http.URL, request.ID)
+		res.ProofUri = &proofUri
+		res.FulfillTxHash = crypto.Keccak256(request.ID, []byte("fulfill"))
+	}
+	return res, nil
+}
+
+// proofRequest is the details of the request, the caller holds the lock
+func (f *FakeProverNetwork) proofRequest(request *FakeProofRequest) *sp1_proto.ProofRequest {
+	return &sp1_proto.ProofRequest{
+		RequestId:         request.ID,
+		VkHash:            request.Body.VkHash,
+		Version:           request.Body.Version,
+		Mode:              request.Body.Mode,
+		Strategy:          request.Body.Strategy,
+		StdinUri:          request.Body.StdinUri,
+		Deadline:          request.Body.Deadline,
+		CycleLimit:        request.Body.CycleLimit,
+		FulfillmentStatus: request.status.Fulfillment,
+		ExecutionStatus:   request.status.Execution,
+		Requester:         request.Requester[:],
+		TxHash:            crypto.Keccak256(request.ID),
+	}
+}
+
+func (f *FakeProverNetwork) GetProofRequestDetails(ctx context.Context, req *sp1_proto.GetProofRequestDetailsRequest) (*sp1_proto.GetProofRequestDetailsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	request, err := f.request(req.RequestId)
+	if err != nil {
+		return &sp1_proto.GetProofRequestDetailsResponse{}, nil
+	}
+	return &sp1_proto.GetProofRequestDetailsResponse{Request: f.proofRequest(request)}, nil
+}
+
+func (f *FakeProverNetwork) GetFilteredProofRequests(ctx context.Context, req *sp1_proto.GetFilteredProofRequestsRequest) (*sp1_proto.GetFilteredProofRequestsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var requests []*sp1_proto.ProofRequest
+	for _, request := range f.requests {
+		switch {
+		case req.Requester != nil && common.BytesToAddress(req.Requester) != request.Requester,
+			req.FulfillmentStatus != nil && *req.FulfillmentStatus != request.status.Fulfillment,
+			req.ExecutionStatus != nil && *req.ExecutionStatus != request.status.Execution,
+			req.MinimumDeadline != nil && *req.MinimumDeadline > request.Body.Deadline,
+			req.VkHash != nil && !bytes.Equal(req.VkHash, request.Body.VkHash):
+			continue
+		}
+		requests = append(requests, f.proofRequest(request))
+	}
+	if req.Limit != nil {
+		offset := int(*req.Limit) * int(req.GetPage())
+		requests = requests[min(offset, len(requests)):min(offset+int(*req.Limit), len(requests))]
+	}
+	return &sp1_proto.GetFilteredProofRequestsResponse{Requests: requests}, nil
+}
+
+func (f *FakeProverNetwork) CancelRequest(ctx context.Context, req *sp1_proto.CancelRequestRequest) (*sp1_proto.CancelRequestResponse, error) {
+	account, err := signer(req.Body, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	request, err := f.request(req.Body.RequestId)
+	if err != nil {
+		return nil, err
+	}
+	if request.Requester != account {
+		return nil, status.Errorf(codes.PermissionDenied, "request %x is not requested by %v", request.ID, account)
+	}
+	if request.status.Fulfillment != sp1_proto.FulfillmentStatus_Requested {
+		return nil, status.Errorf(codes.FailedPrecondition, "request %x is %v", request.ID, request.status.Fulfillment)
+	}
+	if err := f.useNonce(account, req.Body.Nonce); err != nil {
+		return nil, err
+	}
+	request.Cancelled = true
+	request.status = SP1ProofStatus{sp1_proto.FulfillmentStatus_Unfulfillable, request.status.Execution}
+	return &sp1_proto.CancelRequestResponse{
+		TxHash: crypto.Keccak256(request.ID, []byte("cancel")),
+		Body:   &sp1_proto.CancelRequestResponseBody{},
+	}, nil
+}
+
+func (f *FakeProverNetwork) GetBalance(ctx context.Context, req *sp1_proto.GetBalanceRequest) (*sp1_proto.GetBalanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &sp1_proto.GetBalanceResponse{Amount: f.Balance}, nil
+}
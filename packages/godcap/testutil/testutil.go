@@ -0,0 +1,53 @@
+// Package testutil provides in-process fakes of the Bonsai api and the sp1 prover network,
+// and the groth16 and plonk proof simulators, so the prover clients can be tested offline
+package testutil
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// BONSAI_RECEIPT_IMAGE_ID is the image id of the guest proved in test_receipt_2.hex
+	BONSAI_RECEIPT_IMAGE_ID = "83613a8beec226d1f29714530f1df791fa16c2c4dfcf22c50ab7edac59ca637f"
+)
+
+// ReadBonsaiReceipt decodes a receipt fixture of the bonsai package:
+//   - test_receipt_1.hex: a succinct receipt
+//   - test_receipt_2.hex: a groth16 receipt of BONSAI_RECEIPT_IMAGE_ID
+func ReadBonsaiReceipt(name string) ([]byte, error) {
+	_, file, _, _ := runtime.Caller(0)
+	receiptHex, err := os.ReadFile(filepath.Join(filepath.Dir(file), "..", "bonsai", name))
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(receiptHex)))
+}
+
+// fault fails the next requests matching the key
+type fault struct {
+	key  string
+	code int
+	left int
+}
+
+// takeFault consumes a fault matching the request, the caller holds the lock of the fake
+func takeFault(faults []*fault, match func(key string) bool) (int, bool) {
+	for _, f := range faults {
+		if f.left > 0 && match(f.key) {
+			f.left--
+			return f.code, true
+		}
+	}
+	return 0, false
+}
+
+// nextState advances a scripted state machine, the last state sticks
+func nextState[T any](states []T, polls *int) T {
+	idx := min(*polls, len(states)-1)
+	*polls++
+	return states[idx]
+}
@@ -0,0 +1,157 @@
+package zkdcap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chzyer/logex"
+)
+
+// BatchOptions controls ProveBatch
+type BatchOptions struct {
+	// Concurrency is the maximum number of proofs in flight, defaults to 4
+	Concurrency int
+}
+
+// BatchResult is the proof of the quote at Index of the batch
+type BatchResult struct {
+	Index int
+	Proof *ZkProof
+	Err   error
+}
+
+// rateLimiter spaces the submissions to a backend by interval
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return logex.Trace(ctx.Err())
+	}
+}
+
+// SetRateLimit sets the minimum interval between two proofs submitted to the backend of ty.
+// The limit is shared by all the batches of the client, 0 disables it.
+func (c *ZkProofClient) SetRateLimit(ty ZkType, interval time.Duration) {
+	c.limitersMu.Lock()
+	defer c.limitersMu.Unlock()
+	if interval <= 0 {
+		delete(c.limiters, ty)
+		return
+	}
+	if c.limiters == nil {
+		c.limiters = make(map[ZkType]*rateLimiter)
+	}
+	c.limiters[ty] = &rateLimiter{interval: interval}
+}
+
+func (c *ZkProofClient) waitRateLimit(ctx context.Context, ty ZkType) error {
+	c.limitersMu.Lock()
+	limiter := c.limiters[ty]
+	c.limitersMu.Unlock()
+	if limiter == nil {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// ProveBatch proves the quotes with a bounded number of workers. The collateral is fetched once
+// per CollateralKey. Results are streamed in the order they finish, the channel is closed at the end.
+func (c *ZkProofClient) ProveBatch(ctx context.Context, ty ZkType, quotes [][]byte, opts *BatchOptions) <-chan *BatchResult {
+	results := make(chan *BatchResult, len(quotes))
+	prover, err := c.Prover(ty)
+	if err != nil {
+		for idx := range quotes {
+			results <- &BatchResult{Index: idx, Err: logex.Trace(err)}
+		}
+		close(results)
+		return results
+	}
+
+	if opts == nil {
+		opts = new(BatchOptions)
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	if concurrency > len(quotes) {
+		concurrency = len(quotes)
+	}
+
+	var collaterals *CollateralCache
+	if NeedsCollateral(prover) {
+		collaterals = NewCollateralCache(c.ps)
+	}
+	indexes := make(chan int, len(quotes))
+	for idx := range quotes {
+		indexes <- idx
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				proof, err := c.proveBatchItem(ctx, ty, quotes[idx], collaterals)
+				results <- &BatchResult{Index: idx, Proof: proof, Err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func (c *ZkProofClient) proveBatchItem(ctx context.Context, ty ZkType, quote []byte, collaterals *CollateralCache) (proof *ZkProof, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, logex.Trace(err)
+	}
+	var collateral *Collateral
+	if collaterals != nil {
+		if c.ps == nil {
+			return nil, logex.NewErrorf("pccs client is required to fetch the collateral")
+		}
+		quoteParser, err := parseQuote(quote)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		collateral, err = collaterals.Get(ctx, quoteParser)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+	}
+	if err := c.waitRateLimit(ctx, ty); err != nil {
+		return nil, logex.Trace(err)
+	}
+	proof, err = c.ProveQuote(ctx, ty, quote, collateral)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return proof, nil
+}
@@ -0,0 +1,61 @@
+package zkdcap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/chzyer/test"
+)
+
+func TestProveBatch(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	client, err := NewZkProofClient(&ZkProofConfig{Provers: []Prover{&MockProver{}}}, nil)
+	test.Nil(err)
+	client.SetRateLimit(ZkTypeMock, 5*time.Millisecond)
+
+	var quotes [][]byte
+	for i := 0; i < 10; i++ {
+		quotes = append(quotes, mock.Quotes[i%len(mock.Quotes)])
+	}
+	quotes = append(quotes, []byte("invalid"))
+
+	start := time.Now()
+	seen := make(map[int]bool)
+	for result := range client.ProveBatch(ctx, ZkTypeMock, quotes, &BatchOptions{Concurrency: 3}) {
+		test.True(!seen[result.Index])
+		seen[result.Index] = true
+		if result.Index == len(quotes)-1 {
+			test.NotNil(result.Err)
+			continue
+		}
+		test.Nil(result.Err)
+		test.Nil(VerifyMockProof(result.Proof))
+	}
+	test.Equal(len(seen), len(quotes))
+	test.True(time.Since(start) >= 9*5*time.Millisecond)
+
+	for result := range client.ProveBatch(ctx, ZkTypeRiscZero+100, quotes[:2], nil) {
+		test.NotNil(result.Err)
+	}
+}
+
+func TestCollateralKey(t *testing.T) {
+	defer test.New(t)
+	sgx, err := CollateralKey(parser.NewQuoteParser(mock.Quotes[0]))
+	test.Nil(err)
+	tdx, err := CollateralKey(parser.NewQuoteParser(mock.Quotes[1]))
+	test.Nil(err)
+	test.Equal(sgx, "0300-00000000-00606a000000-2")
+	test.Equal(tdx, "0400-81000000-90c06f000000-2")
+
+	_, err = parseQuote([]byte("invalid"))
+	test.NotNil(err)
+	quoteParser, err := parseQuote(mock.Quotes[1][:parser.QUOTE_HEADER_SIZE+10])
+	test.Nil(err)
+	_, err = CollateralKey(quoteParser)
+	test.NotNil(err)
+}
@@ -1,10 +1,14 @@
 package zkdcap
 
 import (
+	"context"
 	"encoding/binary"
-	"time"
+	"sync"
 
 	_ "embed"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bonsai"
+	"github.com/chzyer/logex"
 )
 
 const BONSAI_IMAGE_ID = "d6c3b4b08fa163dd44f89125f97223f6f7163e3f0f62e360d707adab8f6b7799"
@@ -12,31 +16,218 @@ const BONSAI_IMAGE_ID = "d6c3b4b08fa163dd44f89125f97223f6f7163e3f0f62e360d707ada
 //go:embed elf/bonsai_dcap_guest.elf
 var BONSAI_DCAP_GUEST_ELF []byte
 
+// BonsaiGenerateInput encodes the guest input verified at the current time
 func BonsaiGenerateInput(quote []byte, collateral *Collateral) []byte {
-	currentTime := uint64(time.Now().Unix())
+	return NewGuestInput(quote, collateral, nil).Encode()
+}
 
-	var currentTimeBytes [8]byte
-	binary.LittleEndian.PutUint64(currentTimeBytes[:], currentTime)
+// BonsaiProver proves the guest on the RISC Zero Bonsai service
+type BonsaiProver struct {
+	Client *bonsai.Client
+	// ImageID and Elf of the guest, default to BONSAI_IMAGE_ID and BONSAI_DCAP_GUEST_ELF
+	ImageID string
+	Elf     []byte
 
-	collaterals := collateral.Encode()
+	mu            sync.Mutex
+	imageUploaded bool
+}
 
-	quoteLen := uint32(len(quote))
-	collateralsLen := uint32(len(collaterals))
+// NewBonsaiProver is the ProverFactory of ZkTypeRiscZero
+func NewBonsaiProver(cfg *ZkProofConfig) (Prover, error) {
+	// cfg.Bonsai is left untouched, it may be shared by other clients
+	bonsaiCfg := new(bonsai.Config)
+	if cfg.Bonsai != nil {
+		*bonsaiCfg = *cfg.Bonsai
+	}
+	if bonsaiCfg.Observer == nil {
+		bonsaiCfg.Observer = cfg.Observer
+	}
+	if err := bonsaiCfg.Init(); err != nil {
+		return nil, logex.Trace(err)
+	}
+	program, err := cfg.Program()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if program.ImageID == "" {
+		// the program isn't built for risc0
+		return nil, nil
+	}
+	elf, err := program.LoadElf()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	prover := &BonsaiProver{ImageID: program.ImageID, Elf: elf}
+	if bonsaiCfg.ApiKey != "" {
+		client, err := bonsai.NewClient(bonsaiCfg)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		prover.Client = client
+	}
+	return prover, nil
+}
 
-	totalLen := 8 + 4 + 4 + quoteLen + collateralsLen
+func (p *BonsaiProver) Type() ZkType {
+	return ZkTypeRiscZero
+}
 
-	data := make([]byte, 0, totalLen)
+func (p *BonsaiProver) ProgramID() string {
+	if p.ImageID == "" {
+		return BONSAI_IMAGE_ID
+	}
+	return p.ImageID
+}
 
-	data = append(data, currentTimeBytes[:]...)
+func (p *BonsaiProver) elf() []byte {
+	if p.Elf == nil {
+		return BONSAI_DCAP_GUEST_ELF
+	}
+	return p.Elf
+}
 
-	var lenBuf [4]byte
-	binary.LittleEndian.PutUint32(lenBuf[:], quoteLen)
-	data = append(data, lenBuf[:]...)
-	binary.LittleEndian.PutUint32(lenBuf[:], collateralsLen)
-	data = append(data, lenBuf[:]...)
+// ProofModes are the Groth16 receipt and the succinct receipt of the session
+func (p *BonsaiProver) ProofModes() []ProofMode {
+	return []ProofMode{ProofModeGroth16, ProofModeSuccinct}
+}
 
-	data = append(data, quote...)
-	data = append(data, collaterals...)
+// Verify verifies the Groth16 seal of the proof off-chain
+func (p *BonsaiProver) Verify(proof *ZkProof) error {
+	return VerifyRiscZeroProof(proof, p.ProgramID())
+}
+
+func (p *BonsaiProver) PrepareInput(quote []byte, collateral *Collateral, opts *InputOptions) ([]byte, error) {
+	return NewGuestInput(quote, collateral, opts).Encode(), nil
+}
+
+func (p *BonsaiProver) Prove(ctx context.Context, input []byte, mode ProofMode) (*ZkProof, error) {
+	job, err := p.Submit(ctx, input, mode)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return p.Resume(ctx, job, nil)
+}
+
+// Submit uploads the image and the input, and creates the proving session
+func (p *BonsaiProver) Submit(ctx context.Context, input []byte, mode ProofMode) (*ProofJob, error) {
+	if p.Client == nil {
+		return nil, logex.NewError("BONSAI_API_KEY is required")
+	}
+	if !SupportsMode(p, mode) {
+		return nil, ErrUnsupportedProofMode.Format(mode, p.Type())
+	}
+	if err := p.uploadImage(ctx); err != nil {
+		return nil, logex.Trace(err)
+	}
+	sess, err := p.Client.Submit(ctx, p.ProgramID(), input)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	job := NewProofJob(ZkTypeRiscZero, mode, input)
+	job.ID = sess.UUID()
+	job.SessionID = sess.UUID()
+	return job, nil
+}
+
+// uploadImage uploads the image to Bonsai if not already uploaded, once per prover
+func (p *BonsaiProver) uploadImage(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.imageUploaded {
+		return nil
+	}
+	if err := p.Client.UploadImage(ctx, p.ProgramID(), p.elf()); err != nil {
+		return logex.Trace(err)
+	}
+	p.imageUploaded = true
+	return nil
+}
+
+// Resume polls the session, then creates and polls the snark session for the Groth16 receipt.
+// The succinct receipt of the session is returned in ProofModeSuccinct.
+func (p *BonsaiProver) Resume(ctx context.Context, job *ProofJob, checkpoint func(*ProofJob) error) (*ZkProof, error) {
+	if p.Client == nil {
+		return nil, logex.NewError("BONSAI_API_KEY is required")
+	}
+	if job.Type != ZkTypeRiscZero || job.SessionID == "" {
+		return nil, logex.NewErrorf("not a bonsai job: %v", job.ID)
+	}
+	polling := p.Client.PollInterval()
+	if job.SnarkID == "" {
+		sess := p.Client.Session(job.SessionID)
+		info, err := sess.Poll(ctx, polling)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		if job.Mode == ProofModeSuccinct {
+			proof, err := RiscZeroSuccinctProof(info.Receipt, info.ReceiptBytes, p.ProgramID())
+			if err != nil {
+				return nil, logex.Trace(err)
+			}
+			return proof, nil
+		}
+		snarkSess, err := sess.CreateSnark(ctx)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		job.SnarkID = snarkSess.UUID()
+		if checkpoint != nil {
+			if err := checkpoint(job); err != nil {
+				return nil, logex.Trace(err)
+			}
+		}
+	}
+	receipt, err := p.Client.SnarkSession(job.SnarkID).Poll(ctx, polling)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	proof, err := RiscZeroProofFromReceipt(receipt, p.ProgramID())
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return proof, nil
+}
+
+// RiscZeroProofFromReceipt encodes the Groth16 receipt for the portal.
+// The claim of the receipt must commit to the image and to the journal, which becomes the output of the proof.
+func RiscZeroProofFromReceipt(receipt *bonsai.Receipt, imageId string) (*ZkProof, error) {
+	groth16 := receipt.Inner.Groth16
+	if groth16 == nil {
+		return nil, logex.NewErrorf("snark receipt is not groth16")
+	}
+	id, err := bonsai.DigestFromHex(imageId)
+	if err != nil {
+		return nil, logex.Trace(err, "imageId")
+	}
+	if err := receipt.VerifyClaim(id); err != nil {
+		return nil, logex.Trace(err)
+	}
+	var selector [4]byte
+	binary.LittleEndian.PutUint32(selector[:], groth16.VerifierParameters[0])
+	return &ZkProof{
+		Type:   ZkTypeRiscZero,
+		Output: []byte(receipt.Journal.Bytes),
+		Proof:  bonsai.Groth16Encode(selector, []byte(groth16.Seal)),
+	}, nil
+}
 
-	return data
+// RiscZeroSuccinctProof returns the bincode encoded succinct receipt as the proof, e.g. for recursion.
+// The claim of the receipt must commit to the image and to the journal, which becomes the output of the proof.
+func RiscZeroSuccinctProof(receipt *bonsai.Receipt, receiptBytes []byte, imageId string) (*ZkProof, error) {
+	if receipt.Inner.Succinct == nil {
+		return nil, logex.NewErrorf("session receipt is not succinct")
+	}
+	id, err := bonsai.DigestFromHex(imageId)
+	if err != nil {
+		return nil, logex.Trace(err, "imageId")
+	}
+	if err := receipt.VerifyClaim(id); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &ZkProof{
+		Type:   ZkTypeRiscZero,
+		Mode:   ProofModeSuccinct,
+		Output: []byte(receipt.Journal.Bytes),
+		Proof:  receiptBytes,
+	}, nil
 }
@@ -0,0 +1,187 @@
+package zkdcap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ProofCacheKey identifies the proof of a quote and its collateral within a timestamp bucket
+type ProofCacheKey struct {
+	Type             ZkType      `json:"type"`
+	ProgramID        string      `json:"program_id"`
+	Mode             ProofMode   `json:"mode"`
+	QuoteDigest      common.Hash `json:"quote_digest"`
+	CollateralDigest common.Hash `json:"collateral_digest"`
+	Bucket           int64       `json:"bucket"`
+}
+
+// NewProofCacheKey computes the key, the timestamp is truncated by bucket
+func NewProofCacheKey(prover Prover, mode ProofMode, quote []byte, collateral *Collateral, timestamp time.Time, bucket time.Duration) *ProofCacheKey {
+	var collateralBytes []byte
+	if collateral != nil {
+		collateralBytes = collateral.Encode()
+	}
+	bucketSecs := int64(bucket / time.Second)
+	if bucketSecs <= 0 {
+		bucketSecs = 1
+	}
+	return &ProofCacheKey{
+		Type:             prover.Type(),
+		ProgramID:        prover.ProgramID(),
+		Mode:             mode,
+		QuoteDigest:      sha256.Sum256(quote),
+		CollateralDigest: sha256.Sum256(collateralBytes),
+		Bucket:           timestamp.Unix() / bucketSecs,
+	}
+}
+
+func (k *ProofCacheKey) String() string {
+	return fmt.Sprintf("%v-%v-%v-%x-%x-%v", k.Type, k.ProgramID, k.Mode, k.QuoteDigest, k.CollateralDigest, k.Bucket)
+}
+
+// ProofCache stores generated proofs to avoid proving the same quote and collateral again
+type ProofCache interface {
+	// Get returns nil if the proof is not cached
+	Get(key *ProofCacheKey) (*ZkProof, error)
+	Put(key *ProofCacheKey, proof *ZkProof) error
+	// Retain removes the proofs of ty generated by other programs than programID
+	Retain(ty ZkType, programID string) error
+}
+
+type proofCacheEntry struct {
+	Key   *ProofCacheKey `json:"key"`
+	Proof *ZkProof       `json:"proof"`
+}
+
+// MemoryProofCache keeps the proofs in memory
+type MemoryProofCache struct {
+	mu      sync.Mutex
+	entries map[string]*proofCacheEntry
+}
+
+func NewMemoryProofCache() *MemoryProofCache {
+	return &MemoryProofCache{entries: make(map[string]*proofCacheEntry)}
+}
+
+func (c *MemoryProofCache) Get(key *ProofCacheKey) (*ZkProof, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key.String()]
+	if !ok {
+		return nil, nil
+	}
+	return cloneProof(entry.Proof), nil
+}
+
+func (c *MemoryProofCache) Put(key *ProofCacheKey, proof *ZkProof) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	copied := *key
+	c.entries[key.String()] = &proofCacheEntry{Key: &copied, Proof: cloneProof(proof)}
+	return nil
+}
+
+func (c *MemoryProofCache) Retain(ty ZkType, programID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, entry := range c.entries {
+		if entry.Key.Type == ty && entry.Key.ProgramID != programID {
+			delete(c.entries, name)
+		}
+	}
+	return nil
+}
+
+// cloneProof copies the proof, the cached proofs aren't shared with the callers
+func cloneProof(proof *ZkProof) *ZkProof {
+	copied := *proof
+	copied.Output = common.CopyBytes(proof.Output)
+	copied.Proof = common.CopyBytes(proof.Proof)
+	return &copied
+}
+
+// FileProofCache keeps each proof as a json file in Dir
+type FileProofCache struct {
+	Dir string
+}
+
+func NewFileProofCache(dir string) (*FileProofCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &FileProofCache{Dir: dir}, nil
+}
+
+func (c *FileProofCache) path(key *ProofCacheKey) string {
+	hash := sha256.Sum256([]byte(key.String()))
+	return filepath.Join(c.Dir, hex.EncodeToString(hash[:])+".json")
+}
+
+func (c *FileProofCache) Get(key *ProofCacheKey) (*ZkProof, error) {
+	data, err := os.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	var entry proofCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, logex.Trace(err)
+	}
+	if entry.Key == nil || entry.Key.String() != key.String() {
+		return nil, nil
+	}
+	return entry.Proof, nil
+}
+
+func (c *FileProofCache) Put(key *ProofCacheKey, proof *ZkProof) error {
+	data, err := json.Marshal(&proofCacheEntry{Key: key, Proof: proof})
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if err := writeFileAtomic(c.path(key), data); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+func (c *FileProofCache) Retain(ty ZkType, programID string) error {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	for _, file := range entries {
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		path := filepath.Join(c.Dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return logex.Trace(err)
+		}
+		var entry proofCacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Key == nil {
+			// drop the corrupted entries as well
+			os.Remove(path)
+			continue
+		}
+		if entry.Key.Type == ty && entry.Key.ProgramID != programID {
+			if err := os.Remove(path); err != nil {
+				return logex.Trace(err)
+			}
+		}
+	}
+	return nil
+}
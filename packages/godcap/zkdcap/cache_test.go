@@ -0,0 +1,67 @@
+package zkdcap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/chzyer/test"
+)
+
+type countingProver struct {
+	MockProver
+	programID string
+	proved    int
+}
+
+func (p *countingProver) ProgramID() string { return p.programID }
+
+func (p *countingProver) Prove(ctx context.Context, input []byte, mode ProofMode) (*ZkProof, error) {
+	p.proved++
+	return p.MockProver.Prove(ctx, input, mode)
+}
+
+func TestProofCache(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	fileCache, err := NewFileProofCache(t.TempDir())
+	test.Nil(err)
+	now := time.Unix(1742789100, 0)
+	clock := func() time.Time { return now }
+
+	for _, cache := range []ProofCache{NewMemoryProofCache(), fileCache} {
+		prover := &countingProver{programID: "v1"}
+		client, err := NewZkProofClient(&ZkProofConfig{Provers: []Prover{prover}, ProofCache: cache, Now: clock}, nil)
+		test.Nil(err)
+
+		proof, err := client.ProveQuote(ctx, ZkTypeMock, mock.Quotes[0], nil)
+		test.Nil(err)
+		cached, err := client.ProveQuote(ctx, ZkTypeMock, mock.Quotes[0], nil)
+		test.Nil(err)
+		test.Equal(cached, proof)
+		test.Equal(prover.proved, 1)
+		// the cached proof isn't changed through the returned ones
+		expected := append([]byte{}, proof.Output...)
+		proof.Output[0] ^= 1
+		cached.Proof = nil
+		cached, err = client.ProveQuote(ctx, ZkTypeMock, mock.Quotes[0], nil)
+		test.Nil(err)
+		test.Equal(cached.Output, expected)
+		test.NotNil(cached.Proof)
+		test.Equal(prover.proved, 1)
+
+		_, err = client.ProveQuote(ctx, ZkTypeMock, mock.Quotes[1], nil)
+		test.Nil(err)
+		test.Equal(prover.proved, 2)
+
+		// the image id changed
+		key := NewProofCacheKey(prover, ProofModeGroth16, mock.Quotes[0], nil, now, time.Hour)
+		prover.programID = "v2"
+		_, err = NewZkProofClient(&ZkProofConfig{Provers: []Prover{prover}, ProofCache: cache}, nil)
+		test.Nil(err)
+		proof, err = cache.Get(key)
+		test.Nil(err)
+		test.Nil(proof)
+	}
+}
@@ -3,6 +3,8 @@ package zkdcap
 import (
 	"context"
 	"encoding/binary"
+	"fmt"
+	"sync"
 
 	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
 	"github.com/automata-network/dcap-sdk/packages/godcap/pccs"
@@ -18,6 +20,9 @@ type Collateral struct {
 	RootCaCrl       []byte
 	PckProcessorCrl []byte
 	PckPlatformCrl  []byte
+
+	// BlockNumber is the chain head before the collateral was fetched, it's not encoded into the guest input
+	BlockNumber uint64
 }
 
 func NewCollateralFromQuoteParser(ctx context.Context, parser *parser.QuoteParser, ps *pccs.Client) (*Collateral, error) {
@@ -25,6 +30,10 @@ func NewCollateralFromQuoteParser(ctx context.Context, parser *parser.QuoteParse
 	if err != nil {
 		return nil, logex.Trace(err)
 	}
+	blockNumber, err := ps.BlockNumber(ctx)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
 	pckType, err := parser.PckType(certs[0])
 	if err != nil {
 		return nil, logex.Trace(err)
@@ -75,9 +84,105 @@ func NewCollateralFromQuoteParser(ctx context.Context, parser *parser.QuoteParse
 		RootCaCrl:       rootCert.Crl,
 		PckProcessorCrl: processorCrl,
 		PckPlatformCrl:  platformCrl,
+		BlockNumber:     blockNumber,
 	}, nil
 }
 
+// parseQuote recovers from the panic of parser.NewQuoteParser on malformed quotes
+func parseQuote(quote []byte) (p *parser.QuoteParser, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = logex.NewErrorf("invalid quote: %v", r)
+		}
+	}()
+	if len(quote) < parser.QUOTE_HEADER_SIZE {
+		return nil, logex.NewErrorf("quote too short: %v", len(quote))
+	}
+	return parser.NewQuoteParser(quote), nil
+}
+
+// CollateralKey identifies the collateral of a quote: quote version, tee type, FMSPC and PCK CA.
+// Quotes with the same key share the same collateral.
+func CollateralKey(parser *parser.QuoteParser) (key string, err error) {
+	// the parser panics on malformed quotes
+	defer func() {
+		if r := recover(); r != nil {
+			err = logex.NewErrorf("invalid quote: %v", r)
+		}
+	}()
+	certs, err := parser.Certificates()
+	if err != nil {
+		return "", logex.Trace(err)
+	}
+	pckType, err := parser.PckType(certs[0])
+	if err != nil {
+		return "", logex.Trace(err)
+	}
+	sgxExts, err := parser.SgxExt(certs[0])
+	if err != nil {
+		return "", logex.Trace(err)
+	}
+	quote := parser.Quote()
+	return fmt.Sprintf("%x-%x-%v-%v", quote[0:2], quote[4:8], parser.Fmpsc(sgxExts), pckType), nil
+}
+
+// CollateralCache fetches the collateral once per CollateralKey
+type CollateralCache struct {
+	ps      *pccs.Client
+	mu      sync.Mutex
+	entries map[string]*collateralEntry
+}
+
+type collateralEntry struct {
+	done       chan struct{}
+	collateral *Collateral
+	err        error
+}
+
+func NewCollateralCache(ps *pccs.Client) *CollateralCache {
+	return &CollateralCache{ps: ps, entries: make(map[string]*collateralEntry)}
+}
+
+// Get returns the cached collateral of the quote, concurrent calls of the same key share one fetch.
+// Failed fetches are not cached.
+func (c *CollateralCache) Get(ctx context.Context, parser *parser.QuoteParser) (*Collateral, error) {
+	key, err := CollateralKey(parser)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &collateralEntry{done: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	if ok {
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, logex.Trace(ctx.Err())
+		}
+		if entry.err != nil {
+			return nil, logex.Trace(entry.err)
+		}
+		return entry.collateral, nil
+	}
+
+	entry.collateral, entry.err = NewCollateralFromQuoteParser(ctx, parser, c.ps)
+	if entry.err != nil {
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+	}
+	close(entry.done)
+	if entry.err != nil {
+		return nil, logex.Trace(entry.err)
+	}
+	return entry.collateral, nil
+}
+
 // Modified from https://github.com/automata-network/dcap-rs/blob/b218a9dcdf2aec8ee05f4d2bd055116947ddfced/src/types/collaterals.rs#L35-L105
 func (c *Collateral) Encode() []byte {
 	tcbInfo := c.TcbInfo.Encode()
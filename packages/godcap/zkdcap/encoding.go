@@ -0,0 +1,200 @@
+package zkdcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	ErrInvalidZkProof             = logex.Define("invalid zk proof: %v")
+	ErrUnsupportedEncodingVersion = logex.Define("unsupported zk proof encoding version: %v")
+)
+
+const (
+	// ZKPROOF_ENCODING_VERSION is the version written by the encoders of ZkProof
+	ZKPROOF_ENCODING_VERSION = 1
+	// ZKPROOF_MAX_PROGRAM_ID_SIZE limits the program id, e.g. a hex image id or vkhash
+	ZKPROOF_MAX_PROGRAM_ID_SIZE = 256
+)
+
+// ZKPROOF_BINARY_MAGIC prefixes the binary encoding of ZkProof
+var ZKPROOF_BINARY_MAGIC = [4]byte{'z', 'k', 'p', 'f'}
+
+// Validate checks the fields of the proof, it's called by the decoders.
+// The types other than ZkTypeMock need a prover registered by RegisterProver.
+func (p *ZkProof) Validate() error {
+	if !knownZkType(p.Type) {
+		return ErrInvalidZkProof.Format(fmt.Sprintf("unknown type %d", p.Type))
+	}
+	if _, ok := proofModeNames[p.Mode]; !ok {
+		return ErrInvalidZkProof.Format("unknown mode " + p.Mode.String())
+	}
+	if len(p.ProgramID) > ZKPROOF_MAX_PROGRAM_ID_SIZE {
+		return ErrInvalidZkProof.Format("program id too long")
+	}
+	if len(p.Output) < 2 {
+		return ErrInvalidZkProof.Format("output too short")
+	}
+	if size := int(binary.BigEndian.Uint16(p.Output[:2])); len(p.Output) < 2+size {
+		return ErrInvalidZkProof.Format("output shorter than its header")
+	}
+	if len(p.Proof) == 0 {
+		return ErrInvalidZkProof.Format("empty proof")
+	}
+	// the on-chain verifiers are selected by the first 4 bytes
+	if p.Mode.OnChain() && len(p.Proof) < 4 {
+		return ErrInvalidZkProof.Format("missing verifier selector")
+	}
+	return nil
+}
+
+type zkProofJSON struct {
+	Version         uint8         `json:"version"`
+	Type            ZkType        `json:"type"`
+	Mode            ProofMode     `json:"mode"`
+	ProgramID       string        `json:"program_id"`
+	Output          hexutil.Bytes `json:"output"`
+	Proof           hexutil.Bytes `json:"proof"`
+	InputDigest     common.Hash   `json:"input_digest"`
+	CollateralBlock uint64        `json:"collateral_block"`
+}
+
+func (p ZkProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&zkProofJSON{
+		Version:         ZKPROOF_ENCODING_VERSION,
+		Type:            p.Type,
+		Mode:            p.Mode,
+		ProgramID:       p.ProgramID,
+		Output:          p.Output,
+		Proof:           p.Proof,
+		InputDigest:     p.InputDigest,
+		CollateralBlock: p.CollateralBlock,
+	})
+}
+
+func (p *ZkProof) UnmarshalJSON(data []byte) error {
+	var val zkProofJSON
+	if err := json.Unmarshal(data, &val); err != nil {
+		return logex.Trace(err)
+	}
+	if val.Version != ZKPROOF_ENCODING_VERSION {
+		return ErrUnsupportedEncodingVersion.Format(val.Version)
+	}
+	proof := ZkProof{
+		Type:            val.Type,
+		Mode:            val.Mode,
+		ProgramID:       val.ProgramID,
+		Output:          val.Output,
+		Proof:           val.Proof,
+		InputDigest:     val.InputDigest,
+		CollateralBlock: val.CollateralBlock,
+	}
+	if err := proof.Validate(); err != nil {
+		return logex.Trace(err)
+	}
+	*p = proof
+	return nil
+}
+
+// MarshalBinary encodes the proof compactly, big endian:
+//
+//	magic(4) | version(1) | type(1) | mode(1) | collateral block(8) | input digest(32) |
+//	len(program id)(2) | program id | len(output)(4) | output | len(proof)(4) | proof
+func (p *ZkProof) MarshalBinary() ([]byte, error) {
+	if len(p.ProgramID) > ZKPROOF_MAX_PROGRAM_ID_SIZE {
+		return nil, ErrInvalidZkProof.Format("program id too long")
+	}
+	data := make([]byte, 0, 4+3+8+32+2+len(p.ProgramID)+4+len(p.Output)+4+len(p.Proof))
+	data = append(data, ZKPROOF_BINARY_MAGIC[:]...)
+	data = append(data, ZKPROOF_ENCODING_VERSION, uint8(p.Type), uint8(p.Mode))
+	data = binary.BigEndian.AppendUint64(data, p.CollateralBlock)
+	data = append(data, p.InputDigest[:]...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(p.ProgramID)))
+	data = append(data, p.ProgramID...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(p.Output)))
+	data = append(data, p.Output...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(p.Proof)))
+	data = append(data, p.Proof...)
+	return data, nil
+}
+
+func (p *ZkProof) UnmarshalBinary(data []byte) error {
+	if !IsBinaryZkProof(data) {
+		return ErrInvalidZkProof.Format("missing magic")
+	}
+	data = data[len(ZKPROOF_BINARY_MAGIC):]
+	if len(data) < 3+8+32+2 {
+		return ErrInvalidZkProof.Format("header too short")
+	}
+	if data[0] != ZKPROOF_ENCODING_VERSION {
+		return ErrUnsupportedEncodingVersion.Format(data[0])
+	}
+	proof := ZkProof{
+		Type:            ZkType(data[1]),
+		Mode:            ProofMode(data[2]),
+		CollateralBlock: binary.BigEndian.Uint64(data[3:11]),
+		InputDigest:     common.BytesToHash(data[11:43]),
+	}
+	data = data[43:]
+
+	programIdSize := int(binary.BigEndian.Uint16(data[:2]))
+	programId, data, err := readSized(data[2:], programIdSize)
+	if err != nil {
+		return logex.Trace(err, "program id")
+	}
+	proof.ProgramID = string(programId)
+	if proof.Output, data, err = readSized32(data); err != nil {
+		return logex.Trace(err, "output")
+	}
+	if proof.Proof, data, err = readSized32(data); err != nil {
+		return logex.Trace(err, "proof")
+	}
+	if len(data) != 0 {
+		return ErrInvalidZkProof.Format("trailing bytes")
+	}
+	if err := proof.Validate(); err != nil {
+		return logex.Trace(err)
+	}
+	*p = proof
+	return nil
+}
+
+// IsBinaryZkProof reports whether the data is encoded by ZkProof.MarshalBinary
+func IsBinaryZkProof(data []byte) bool {
+	return bytes.HasPrefix(data, ZKPROOF_BINARY_MAGIC[:])
+}
+
+// DecodeZkProof decodes the proof in either the binary or the JSON encoding
+func DecodeZkProof(data []byte) (*ZkProof, error) {
+	var proof ZkProof
+	if IsBinaryZkProof(data) {
+		if err := proof.UnmarshalBinary(data); err != nil {
+			return nil, logex.Trace(err)
+		}
+		return &proof, nil
+	}
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &proof, nil
+}
+
+func readSized32(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, ErrInvalidZkProof.Format("missing length")
+	}
+	return readSized(data[4:], int(binary.BigEndian.Uint32(data[:4])))
+}
+
+func readSized(data []byte, size int) ([]byte, []byte, error) {
+	if size > len(data) {
+		return nil, nil, ErrInvalidZkProof.Format("length out of range")
+	}
+	return data[:size:size], data[size:], nil
+}
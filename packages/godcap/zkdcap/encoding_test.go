@@ -0,0 +1,57 @@
+package zkdcap
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+)
+
+func TestZkProofEncoding(t *testing.T) {
+	defer test.New(t)
+	client, err := NewZkProofClient(&ZkProofConfig{Provers: []Prover{new(MockProver)}}, nil)
+	test.Nil(err)
+	proof, err := client.ProveQuote(context.Background(), ZkTypeMock, mock.Quotes[0], nil)
+	test.Nil(err)
+	test.Equal(proof.ProgramID, new(MockProver).ProgramID())
+	proof.CollateralBlock = 100
+
+	data, err := json.Marshal(proof)
+	test.Nil(err)
+	decoded, err := DecodeZkProof(data)
+	test.Nil(err)
+	test.Equal(decoded, proof)
+
+	data, err = proof.MarshalBinary()
+	test.Nil(err)
+	test.True(IsBinaryZkProof(data))
+	decoded, err = DecodeZkProof(data)
+	test.Nil(err)
+	test.Equal(decoded, proof)
+
+	// truncated
+	_, err = DecodeZkProof(data[:len(data)-1])
+	test.True(logex.Equal(err, ErrInvalidZkProof))
+	_, err = DecodeZkProof(append(data, 0))
+	test.True(logex.Equal(err, ErrInvalidZkProof))
+	// the type has no registered prover
+	unknown := append([]byte{}, data...)
+	unknown[5] = 0x7f
+	_, err = DecodeZkProof(unknown)
+	test.True(logex.Equal(err, ErrInvalidZkProof))
+	data[4] = 2
+	_, err = DecodeZkProof(data)
+	test.True(logex.Equal(err, ErrUnsupportedEncodingVersion))
+
+	_, err = DecodeZkProof([]byte(`{"version": 2}`))
+	test.True(logex.Equal(err, ErrUnsupportedEncodingVersion))
+	_, err = DecodeZkProof([]byte(`{"version": 1, "mode": "groth16", "output": "0x0001", "proof": "0x01"}`))
+	test.True(logex.Equal(err, ErrInvalidZkProof))
+	_, err = DecodeZkProof([]byte(`{"version": 1, "mode": "succinct", "output": "0x000100", "proof": "0x01"}`))
+	test.Nil(err)
+	_, err = DecodeZkProof([]byte(`{"version": 1, "type": 127, "mode": "succinct", "output": "0x000100", "proof": "0x01"}`))
+	test.True(logex.Equal(err, ErrInvalidZkProof))
+}
@@ -0,0 +1,183 @@
+package zkdcap
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/automata-network/dcap-sdk/packages/godcap/pccs"
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrStaleProof         = logex.Define("zk proof verified at %v is older than %v")
+	ErrFutureProof        = logex.Define("zk proof verified at %v is later than %v")
+	ErrCollateralMismatch = logex.Define("%v of the zk proof doesn't match the chain: %v != %v")
+)
+
+// MAX_CLOCK_SKEW is the drift accepted between the clock of the proof input and the local clock
+const MAX_CLOCK_SKEW = 5 * time.Minute
+
+// CollateralHashes are the hashes of the collateral verified by the guest,
+// they're checked against the PCCS DAOs by the attestation contract
+type CollateralHashes struct {
+	TcbInfo    common.Hash
+	QeIdentity common.Hash
+	RootCa     common.Hash
+	SigningCa  common.Hash
+	RootCrl    common.Hash
+	PckCrl     common.Hash
+}
+
+// ZkOutput is the output of the guest: len(2) | verified output | timestamp(8) | collateral hashes(6 * 32)
+type ZkOutput struct {
+	Verified *parser.VerifiedOutput
+	// Timestamp at which the quote and the collateral were verified
+	Timestamp  time.Time
+	Collateral CollateralHashes
+}
+
+// DecodeZkOutput decodes the output of the guest
+func DecodeZkOutput(data []byte) (*ZkOutput, error) {
+	verified, err := decodeVerifiedOutput(data)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	rest := data[2+int(binary.BigEndian.Uint16(data[:2])):]
+	if len(rest) != 8+6*common.HashLength {
+		return nil, logex.NewErrorf("invalid zk output trailer size: %v", len(rest))
+	}
+	output := &ZkOutput{
+		Verified:  verified,
+		Timestamp: time.Unix(int64(binary.BigEndian.Uint64(rest[:8])), 0),
+	}
+	rest = rest[8:]
+	for _, hash := range output.Collateral.fields() {
+		*hash.value = common.BytesToHash(rest[:common.HashLength])
+		rest = rest[common.HashLength:]
+	}
+	return output, nil
+}
+
+// DecodeOutput decodes the guest output committed by the proof
+func (p *ZkProof) DecodeOutput() (*ZkOutput, error) {
+	return DecodeZkOutput(p.Output)
+}
+
+type collateralHashField struct {
+	name  string
+	value *common.Hash
+}
+
+// fields are in the order of the guest output
+func (h *CollateralHashes) fields() []collateralHashField {
+	return []collateralHashField{
+		{"tcbInfo", &h.TcbInfo},
+		{"qeIdentity", &h.QeIdentity},
+		{"rootCa", &h.RootCa},
+		{"signingCa", &h.SigningCa},
+		{"rootCrl", &h.RootCrl},
+		{"pckCrl", &h.PckCrl},
+	}
+}
+
+// CheckAge returns ErrStaleProof if the output was verified more than maxAge before now,
+// or ErrFutureProof if it was verified after now, beyond MAX_CLOCK_SKEW
+func (o *ZkOutput) CheckAge(now time.Time, maxAge time.Duration) error {
+	if o.Timestamp.Sub(now) > MAX_CLOCK_SKEW {
+		return ErrFutureProof.Format(o.Timestamp.UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339))
+	}
+	if now.Sub(o.Timestamp) > maxAge {
+		return ErrStaleProof.Format(o.Timestamp.UTC().Format(time.RFC3339), maxAge)
+	}
+	return nil
+}
+
+// CollateralHashSource returns the collateral hashes stored on chain, it's implemented by pccs.Client
+type CollateralHashSource interface {
+	PcsCollateralHash(ctx context.Context, ca uint8, isCrl bool) (common.Hash, error)
+	TcbInfoCollateralHash(ctx context.Context, tcbType uint8, fmspc [6]byte, tcbVersion uint32) (common.Hash, error)
+	EnclaveIDCollateralHash(ctx context.Context, enclaveId uint8, version uint32) (common.Hash, error)
+}
+
+var _ CollateralHashSource = (*pccs.Client)(nil)
+
+// CheckCollateral returns ErrCollateralMismatch if a collateral was updated on chain after the verification.
+// The PCK CRL may be of the processor or the platform CA, which isn't committed by the output.
+func (o *ZkOutput) CheckCollateral(ctx context.Context, chain CollateralHashSource) error {
+	spec, err := o.Verified.QuoteSpec()
+	if err != nil {
+		return logex.Trace(err)
+	}
+	expected := make(map[string][]common.Hash)
+	hash, err := chain.TcbInfoCollateralHash(ctx, spec.TcbType(), o.Verified.Fmspc, spec.TcbVersion())
+	if err != nil {
+		return logex.Trace(err, "tcbInfo")
+	}
+	expected["tcbInfo"] = []common.Hash{hash}
+	if hash, err = chain.EnclaveIDCollateralHash(ctx, spec.EnclaveIDType(), spec.Version()); err != nil {
+		return logex.Trace(err, "qeIdentity")
+	}
+	expected["qeIdentity"] = []common.Hash{hash}
+	for _, pcs := range []struct {
+		name  string
+		ca    uint8
+		isCrl bool
+	}{
+		{"rootCa", pccs.CA_ROOT, false},
+		{"signingCa", pccs.CA_SIGNING, false},
+		{"rootCrl", pccs.CA_ROOT, true},
+		{"pckCrl", pccs.CA_PROCESSOR, true},
+		{"pckCrl", pccs.CA_PLATFORM, true},
+	} {
+		hash, err := chain.PcsCollateralHash(ctx, pcs.ca, pcs.isCrl)
+		if err != nil {
+			return logex.Trace(err, pcs.name)
+		}
+		expected[pcs.name] = append(expected[pcs.name], hash)
+	}
+
+	for _, field := range o.Collateral.fields() {
+		if !containsHash(expected[field.name], *field.value) {
+			return ErrCollateralMismatch.Format(field.name, *field.value, expected[field.name][0])
+		}
+	}
+	return nil
+}
+
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFreshness checks the proof against ZkProofConfig.MaxProofAgeSecs and CheckCollateralHashes
+// before it's submitted, a proof which fails would be rejected on chain or attest outdated collateral
+func (c *ZkProofClient) CheckFreshness(ctx context.Context, proof *ZkProof) error {
+	if c.maxAge <= 0 && !c.checkCollateral {
+		return nil
+	}
+	output, err := proof.DecodeOutput()
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if c.maxAge > 0 {
+		if err := output.CheckAge(c.now(), c.maxAge); err != nil {
+			return logex.Trace(err)
+		}
+	}
+	if c.checkCollateral {
+		if c.ps == nil {
+			return logex.NewErrorf("pccs client is required to check the collateral")
+		}
+		if err := output.CheckCollateral(ctx, c.ps); err != nil {
+			return logex.Trace(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package zkdcap
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/automata-network/dcap-sdk/packages/godcap/pccs"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeCollateralChain serves the collateral hashes of the PCCS DAOs
+type fakeCollateralChain struct {
+	tcbInfo map[uint8]common.Hash
+	enclave map[uint8]common.Hash
+	pcs     map[[2]uint8]common.Hash
+}
+
+func (c *fakeCollateralChain) PcsCollateralHash(ctx context.Context, ca uint8, isCrl bool) (common.Hash, error) {
+	key := [2]uint8{ca, 0}
+	if isCrl {
+		key[1] = 1
+	}
+	return c.pcs[key], nil
+}
+
+func (c *fakeCollateralChain) TcbInfoCollateralHash(ctx context.Context, tcbType uint8, fmspc [6]byte, tcbVersion uint32) (common.Hash, error) {
+	if hex.EncodeToString(fmspc[:]) != "90c06f000000" || tcbVersion != 3 {
+		return common.Hash{}, nil
+	}
+	return c.tcbInfo[tcbType], nil
+}
+
+func (c *fakeCollateralChain) EnclaveIDCollateralHash(ctx context.Context, enclaveId uint8, version uint32) (common.Hash, error) {
+	if version != 4 {
+		return common.Hash{}, nil
+	}
+	return c.enclave[enclaveId], nil
+}
+
+func TestZkOutputFreshness(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	journal, err := hex.DecodeString(testZkOutput)
+	test.Nil(err)
+
+	output, err := (&ZkProof{Output: journal}).DecodeOutput()
+	test.Nil(err)
+	test.True(output.Verified.IsTdx())
+	test.Equal(output.Timestamp, time.Unix(1742789100, 0))
+	hashes := output.Collateral
+
+	test.Nil(output.CheckAge(output.Timestamp.Add(time.Hour), time.Hour))
+	test.True(logex.Equal(output.CheckAge(output.Timestamp.Add(time.Hour+time.Second), time.Hour), ErrStaleProof))
+	// the input may be timestamped by a clock ahead of the local one
+	test.Nil(output.CheckAge(output.Timestamp.Add(-MAX_CLOCK_SKEW), time.Hour))
+	test.True(logex.Equal(output.CheckAge(output.Timestamp.Add(-MAX_CLOCK_SKEW-time.Second), time.Hour), ErrFutureProof))
+
+	chain := &fakeCollateralChain{
+		tcbInfo: map[uint8]common.Hash{1: hashes.TcbInfo},
+		enclave: map[uint8]common.Hash{pccs.ENCLAVE_ID_TDQE: hashes.QeIdentity},
+		pcs: map[[2]uint8]common.Hash{
+			{pccs.CA_ROOT, 0}:     hashes.RootCa,
+			{pccs.CA_SIGNING, 0}:  hashes.SigningCa,
+			{pccs.CA_ROOT, 1}:     hashes.RootCrl,
+			{pccs.CA_PLATFORM, 1}: hashes.PckCrl,
+		},
+	}
+	test.Nil(output.CheckCollateral(ctx, chain))
+
+	// the root CRL is updated after the verification
+	chain.pcs[[2]uint8{pccs.CA_ROOT, 1}] = common.Hash{1}
+	test.True(logex.Equal(output.CheckCollateral(ctx, chain), ErrCollateralMismatch))
+
+	_, err = DecodeZkOutput(journal[:len(journal)-1])
+	test.NotNil(err)
+}
+
+func TestCheckFreshness(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	now := time.Unix(1742789100, 0)
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers:         []Prover{new(MockProver)},
+		MaxProofAgeSecs: 600,
+		Now:             func() time.Time { return now },
+	}, nil)
+	test.Nil(err)
+
+	proof, err := client.ProveQuote(ctx, ZkTypeMock, mock.Quotes[0], nil)
+	test.Nil(err)
+	test.Nil(client.CheckFreshness(ctx, proof))
+	now = now.Add(11 * time.Minute)
+	test.True(logex.Equal(client.CheckFreshness(ctx, proof), ErrStaleProof))
+	now = now.Add(-time.Hour)
+	test.True(logex.Equal(client.CheckFreshness(ctx, proof), ErrFutureProof))
+
+	// the proofs aren't decoded if the checks are disabled
+	client, err = NewZkProofClient(&ZkProofConfig{Provers: []Prover{new(MockProver)}}, nil)
+	test.Nil(err)
+	test.Nil(client.CheckFreshness(ctx, &ZkProof{Output: []byte{0}}))
+
+	client, err = NewZkProofClient(&ZkProofConfig{Provers: []Prover{new(MockProver)}, CheckCollateralHashes: true}, nil)
+	test.Nil(err)
+	test.NotNil(client.CheckFreshness(ctx, proof))
+
+	output, err := proof.DecodeOutput()
+	test.Nil(err)
+	test.Equal(output.Verified.TcbStatus, parser.TcbOK)
+}
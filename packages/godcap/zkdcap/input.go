@@ -0,0 +1,145 @@
+package zkdcap
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/chzyer/logex"
+)
+
+const (
+	GUEST_INPUT_HEADER_SIZE = 8 + 4 + 4
+	COLLATERAL_FIELD_COUNT  = 8
+)
+
+// InputOptions controls the guest input generated by a Prover
+type InputOptions struct {
+	// Timestamp at which the quote is verified by the guest, defaults to the current time
+	Timestamp time.Time
+}
+
+func (o *InputOptions) timestamp(now func() time.Time) time.Time {
+	if o != nil && !o.Timestamp.IsZero() {
+		return o.Timestamp
+	}
+	if now != nil {
+		return now()
+	}
+	return time.Now()
+}
+
+// GuestInput is the input of the DCAP guest program, shared by all the zk backends.
+//
+// Layout: timestamp(u64) | len(quote)(u32) | len(collateral)(u32) | quote | collateral, little endian
+type GuestInput struct {
+	// Timestamp in seconds
+	Timestamp  uint64
+	Quote      []byte
+	Collateral []byte
+}
+
+// NewGuestInput creates the input of the quote and its collateral, the collateral can be nil
+func NewGuestInput(quote []byte, collateral *Collateral, opts *InputOptions) *GuestInput {
+	var collateralBytes []byte
+	if collateral != nil {
+		collateralBytes = collateral.Encode()
+	}
+	return &GuestInput{
+		Timestamp:  uint64(opts.timestamp(nil).Unix()),
+		Quote:      quote,
+		Collateral: collateralBytes,
+	}
+}
+
+// DecodeGuestInput decodes the input generated by GuestInput.Encode
+func DecodeGuestInput(data []byte) (*GuestInput, error) {
+	if len(data) < GUEST_INPUT_HEADER_SIZE {
+		return nil, logex.NewErrorf("input too short: %v", len(data))
+	}
+	quoteLen := uint64(binary.LittleEndian.Uint32(data[8:12]))
+	collateralLen := uint64(binary.LittleEndian.Uint32(data[12:16]))
+	if uint64(len(data)) != GUEST_INPUT_HEADER_SIZE+quoteLen+collateralLen {
+		return nil, logex.NewErrorf("input size mismatch: %v != %v", len(data), GUEST_INPUT_HEADER_SIZE+quoteLen+collateralLen)
+	}
+	quote := data[GUEST_INPUT_HEADER_SIZE : GUEST_INPUT_HEADER_SIZE+quoteLen]
+	return &GuestInput{
+		Timestamp:  binary.LittleEndian.Uint64(data[0:8]),
+		Quote:      quote,
+		Collateral: data[GUEST_INPUT_HEADER_SIZE+quoteLen:],
+	}, nil
+}
+
+// Time returns the timestamp as time.Time
+func (in *GuestInput) Time() time.Time {
+	return time.Unix(int64(in.Timestamp), 0)
+}
+
+func (in *GuestInput) Encode() []byte {
+	data := make([]byte, GUEST_INPUT_HEADER_SIZE, GUEST_INPUT_HEADER_SIZE+len(in.Quote)+len(in.Collateral))
+	binary.LittleEndian.PutUint64(data[0:8], in.Timestamp)
+	binary.LittleEndian.PutUint32(data[8:12], uint32(len(in.Quote)))
+	binary.LittleEndian.PutUint32(data[12:16], uint32(len(in.Collateral)))
+	data = append(data, in.Quote...)
+	data = append(data, in.Collateral...)
+	return data
+}
+
+// DecodeCollateral decodes the collateral of the input, returns nil if the input has no collateral
+func (in *GuestInput) DecodeCollateral() (*Collateral, error) {
+	if len(in.Collateral) == 0 {
+		return nil, nil
+	}
+	return DecodeCollateral(in.Collateral)
+}
+
+// DecodeCollateral decodes the collateral generated by Collateral.Encode
+func DecodeCollateral(data []byte) (*Collateral, error) {
+	fields, err := splitCollateral(data)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	collateral := &Collateral{
+		RootCa:          fields[2],
+		TcbSigningCa:    fields[3],
+		PckCertChain:    fields[4],
+		RootCaCrl:       fields[5],
+		PckProcessorCrl: fields[6],
+		PckPlatformCrl:  fields[7],
+	}
+	if len(fields[0]) > 0 {
+		if err := json.Unmarshal(fields[0], &collateral.TcbInfo); err != nil {
+			return nil, logex.Trace(err, "tcbInfo")
+		}
+	}
+	if len(fields[1]) > 0 {
+		if err := json.Unmarshal(fields[1], &collateral.QeIdentity); err != nil {
+			return nil, logex.Trace(err, "qeIdentity")
+		}
+	}
+	return collateral, nil
+}
+
+// splitCollateral returns the raw fields of the encoded collateral:
+// tcbInfo, qeIdentity, rootCa, signingCa, pckCertChain, rootCrl, processorCrl, platformCrl
+func splitCollateral(data []byte) ([COLLATERAL_FIELD_COUNT][]byte, error) {
+	var fields [COLLATERAL_FIELD_COUNT][]byte
+	if len(data) < 4*COLLATERAL_FIELD_COUNT {
+		return fields, logex.NewErrorf("collateral too short: %v", len(data))
+	}
+	offset := uint64(4 * COLLATERAL_FIELD_COUNT)
+	for i := range fields {
+		size := uint64(binary.LittleEndian.Uint32(data[4*i:]))
+		if offset+size > uint64(len(data)) {
+			return fields, logex.NewErrorf("collateral field %v out of range", i)
+		}
+		if size > 0 {
+			fields[i] = data[offset : offset+size]
+		}
+		offset += size
+	}
+	if offset != uint64(len(data)) {
+		return fields, logex.NewErrorf("collateral size mismatch: %v != %v", len(data), offset)
+	}
+	return fields, nil
+}
@@ -0,0 +1,88 @@
+package zkdcap
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/automata-network/dcap-sdk/packages/godcap/pccs"
+	"github.com/chzyer/test"
+)
+
+func TestGuestInput(t *testing.T) {
+	defer test.New(t)
+	timestamp := time.Unix(1742789100, 0)
+	collateral := &Collateral{
+		TcbInfo:        &pccs.TcbInfo{TcbInfo: json.RawMessage(`{"fmspc":"90c06f000000"}`), Signature: "aa"},
+		QeIdentity:     &pccs.EnclaveIdentityInfo{Identity: json.RawMessage(`{"id":"TD_QE"}`), Signature: "bb"},
+		RootCa:         []byte("root"),
+		TcbSigningCa:   []byte("signing"),
+		RootCaCrl:      []byte("rootCrl"),
+		PckPlatformCrl: []byte("platformCrl"),
+	}
+	quote := mock.Quotes[1]
+
+	for _, prover := range []Prover{new(BonsaiProver), new(Sp1Prover), new(MockProver)} {
+		data, err := prover.PrepareInput(quote, collateral, &InputOptions{Timestamp: timestamp})
+		test.Nil(err)
+
+		collateralBytes := collateral.Encode()
+		test.Equal(len(data), 16+len(quote)+len(collateralBytes))
+		test.Equal(binary.LittleEndian.Uint64(data[0:8]), uint64(timestamp.Unix()))
+		test.Equal(binary.LittleEndian.Uint32(data[8:12]), uint32(len(quote)))
+		test.Equal(binary.LittleEndian.Uint32(data[12:16]), uint32(len(collateralBytes)))
+
+		input, err := DecodeGuestInput(data)
+		test.Nil(err)
+		test.Equal(input.Time(), timestamp)
+		test.Equal(input.Quote, quote)
+		test.Equal(input.Encode(), data)
+
+		decoded, err := input.DecodeCollateral()
+		test.Nil(err)
+		test.Equal(decoded, collateral)
+		test.Equal(decoded.Encode(), collateralBytes)
+	}
+
+	// truncated or trailing bytes
+	data := NewGuestInput(quote, collateral, nil).Encode()
+	for _, bad := range [][]byte{data[:15], data[:len(data)-1], append(data[:len(data):len(data)], 0)} {
+		_, err := DecodeGuestInput(bad)
+		test.NotNil(err)
+	}
+	_, err := DecodeCollateral(collateral.Encode()[:40])
+	test.NotNil(err)
+
+	input, err := DecodeGuestInput(NewGuestInput(quote, nil, nil).Encode())
+	test.Nil(err)
+	decoded, err := input.DecodeCollateral()
+	test.Nil(err)
+	test.Nil(decoded)
+}
+
+func TestProveQuoteAt(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	now := time.Unix(1742789100, 0)
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers: []Prover{new(MockProver)},
+		Now:     func() time.Time { return now },
+	}, nil)
+	test.Nil(err)
+
+	outputTime := func(proof *ZkProof) uint64 {
+		size := int(binary.BigEndian.Uint16(proof.Output[:2]))
+		return binary.BigEndian.Uint64(proof.Output[2+size:])
+	}
+	proof, err := client.ProveQuote(ctx, ZkTypeMock, mock.Quotes[0], nil)
+	test.Nil(err)
+	test.Equal(outputTime(proof), uint64(now.Unix()))
+
+	at := now.Add(-24 * time.Hour)
+	proof, err = client.ProveQuoteAt(ctx, ZkTypeMock, mock.Quotes[0], nil, at)
+	test.Nil(err)
+	test.Equal(outputTime(proof), uint64(at.Unix()))
+}
@@ -0,0 +1,228 @@
+package zkdcap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	ErrJobNotFound  = logex.Define("proof job not found: %v")
+	ErrInvalidJobID = logex.Define("invalid proof job id: %q")
+)
+
+// ProofJob is a serializable handle of a submitted proof, which can be resumed after a restart
+type ProofJob struct {
+	// ID identifies the job in a JobStore
+	ID   string    `json:"id"`
+	Type ZkType    `json:"type"`
+	Mode ProofMode `json:"mode"`
+
+	// Bonsai session and snark session
+	SessionID string `json:"session_id,omitempty"`
+	SnarkID   string `json:"snark_id,omitempty"`
+
+	// SP1 request id
+	RequestID hexutil.Bytes `json:"request_id,omitempty"`
+
+	// SHA-256 of the guest input
+	InputDigest common.Hash `json:"input_digest"`
+	// CollateralBlock is the block at which the collateral was fetched
+	CollateralBlock uint64    `json:"collateral_block,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// NewProofJob creates a job of the input, the backend ids are filled by the prover
+// and CreatedAt by the clock of ZkProofClient.SubmitProof
+func NewProofJob(ty ZkType, mode ProofMode, input []byte) *ProofJob {
+	return &ProofJob{
+		Type:        ty,
+		Mode:        mode,
+		InputDigest: sha256.Sum256(input),
+	}
+}
+
+// AsyncProver is a Prover whose proofs can be submitted and resumed separately
+type AsyncProver interface {
+	Prover
+	// Submit starts proving the input without waiting for the proof
+	Submit(ctx context.Context, input []byte, mode ProofMode) (*ProofJob, error)
+	// Resume waits for the proof of the job, checkpoint is called when the job advances to a new stage
+	Resume(ctx context.Context, job *ProofJob, checkpoint func(*ProofJob) error) (*ZkProof, error)
+}
+
+// JobStore persists the pending proof jobs
+type JobStore interface {
+	Save(job *ProofJob) error
+	Load(id string) (*ProofJob, error)
+	Delete(id string) error
+	List() ([]*ProofJob, error)
+}
+
+// MemoryJobStore keeps the jobs in memory
+type MemoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]ProofJob
+}
+
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: make(map[string]ProofJob)}
+}
+
+func (s *MemoryJobStore) Save(job *ProofJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s *MemoryJobStore) Load(id string) (*ProofJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound.Format(id)
+	}
+	return &job, nil
+}
+
+func (s *MemoryJobStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+func (s *MemoryJobStore) List() ([]*ProofJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*ProofJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		job := job
+		jobs = append(jobs, &job)
+	}
+	sortJobs(jobs)
+	return jobs, nil
+}
+
+// FileJobStore keeps each job as a json file in Dir
+type FileJobStore struct {
+	Dir string
+}
+
+func NewFileJobStore(dir string) (*FileJobStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &FileJobStore{Dir: dir}, nil
+}
+
+// path rejects the ids which would resolve outside of Dir
+func (s *FileJobStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", ErrInvalidJobID.Format(id)
+	}
+	return filepath.Join(s.Dir, id+".json"), nil
+}
+
+func (s *FileJobStore) Save(job *ProofJob) error {
+	path, err := s.path(job.ID)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	data, err := json.MarshalIndent(job, "", "\t")
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+func (s *FileJobStore) Load(id string) (*ProofJob, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrJobNotFound.Format(id)
+	}
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	var job ProofJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, logex.Trace(err, id)
+	}
+	return &job, nil
+}
+
+func (s *FileJobStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+func (s *FileJobStore) List() ([]*ProofJob, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	var jobs []*ProofJob
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		job, err := s.Load(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		jobs = append(jobs, job)
+	}
+	sortJobs(jobs)
+	return jobs, nil
+}
+
+func sortJobs(jobs []*ProofJob) {
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+}
+
+// writeFileAtomic writes to a temporary file first, so that a crash never leaves a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return logex.Trace(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return logex.Trace(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return logex.Trace(err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
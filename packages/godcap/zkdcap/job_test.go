@@ -0,0 +1,174 @@
+package zkdcap
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bonsai"
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1"
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1/sp1_proto"
+	"github.com/automata-network/dcap-sdk/packages/godcap/testutil"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestFileJobStore(t *testing.T) {
+	defer test.New(t)
+	store, err := NewFileJobStore(t.TempDir())
+	test.Nil(err)
+
+	job := NewProofJob(ZkTypeSuccinct, ProofModePlonk, []byte("input"))
+	job.ID = "0102"
+	job.RequestID = []byte{1, 2}
+	test.Nil(store.Save(job))
+
+	loaded, err := store.Load(job.ID)
+	test.Nil(err)
+	test.Equal(loaded.RequestID, job.RequestID)
+	test.Equal(loaded.Mode, ProofModePlonk)
+	test.Equal(loaded.InputDigest, job.InputDigest)
+	test.True(loaded.CreatedAt.Equal(job.CreatedAt))
+
+	jobs, err := store.List()
+	test.Nil(err)
+	test.Equal(len(jobs), 1)
+
+	test.Nil(store.Delete(job.ID))
+	_, err = store.Load(job.ID)
+	test.True(logex.Equal(err, ErrJobNotFound))
+
+	// the ids can't escape the directory
+	_, err = store.Load("../0102")
+	test.True(logex.Equal(err, ErrInvalidJobID))
+	job.ID = `..\0102`
+	test.True(logex.Equal(store.Save(job), ErrInvalidJobID))
+	test.True(logex.Equal(store.Delete(".."), ErrInvalidJobID))
+}
+
+func TestResumeProof(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	server := testutil.NewFakeBonsai(t)
+
+	store, err := NewFileJobStore(t.TempDir())
+	test.Nil(err)
+	newClient := func(imageId string) *ZkProofClient {
+		bonsaiClient, err := bonsai.NewClient(&bonsai.Config{Url: server.URL, ApiKey: "key", PollIntervalSecs: 1})
+		test.Nil(err)
+		client, err := NewZkProofClient(&ZkProofConfig{
+			Provers:  []Prover{&BonsaiProver{Client: bonsaiClient, ImageID: imageId}},
+			JobStore: store,
+		}, nil)
+		test.Nil(err)
+		return client
+	}
+
+	job, err := newClient(testutil.BONSAI_RECEIPT_IMAGE_ID).SubmitProof(ctx, ZkTypeRiscZero, []byte("quote"), &Collateral{})
+	test.Nil(err)
+	test.Equal(job.SessionID, "session-1")
+
+	// the receipt doesn't prove the default image, the job is kept
+	_, err = newClient("").ResumeProof(ctx, job)
+	test.True(logex.Equal(err, bonsai.ErrImageIdMismatch))
+
+	// restart
+	client := newClient(testutil.BONSAI_RECEIPT_IMAGE_ID)
+	jobs, err := client.Jobs().List()
+	test.Nil(err)
+	test.Equal(len(jobs), 1)
+	proof, err := client.ResumeProof(ctx, jobs[0])
+	test.Nil(err)
+	test.Equal(len(proof.Output), 799)
+	test.Equal(len(server.Sessions()), 1)
+
+	jobs, err = client.Jobs().List()
+	test.Nil(err)
+	test.Equal(len(jobs), 0)
+}
+
+func TestBonsaiProverFailure(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	server := testutil.NewFakeBonsai(t)
+	server.SessionStates = []string{"RUNNING", "FAILED"}
+	server.ErrorMsg = "guest panicked"
+	// the transient errors are retried
+	server.Fail("/sessions/status/", http.StatusServiceUnavailable, 2)
+
+	bonsaiClient, err := bonsai.NewClient(&bonsai.Config{Url: server.URL, ApiKey: "key", PollIntervalSecs: 1, RetryBackoffMs: 1})
+	test.Nil(err)
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers: []Prover{&BonsaiProver{Client: bonsaiClient, ImageID: testutil.BONSAI_RECEIPT_IMAGE_ID}},
+	}, nil)
+	test.Nil(err)
+	_, err = client.ProveQuote(ctx, ZkTypeRiscZero, []byte("quote"), &Collateral{})
+	test.True(logex.Equal(err, bonsai.ErrSessionFailed))
+	test.True(strings.Contains(err.Error(), "guest panicked"))
+	test.True(len(server.Input("input-1")) > 0)
+
+	server.SessionStates = []string{"RUNNING"}
+	timeout, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
+	defer cancel()
+	_, err = client.ProveQuote(timeout, ZkTypeRiscZero, []byte("quote"), &Collateral{})
+	test.True(logex.Equal(err, context.DeadlineExceeded))
+}
+
+func TestResumeSp1Proof(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	network := testutil.NewFakeProverNetwork(t)
+	key, err := crypto.GenerateKey()
+	test.Nil(err)
+	sp1Client, err := sp1.NewClient(&sp1.Config{Rpc: network.Rpc, PrivateKey: hex.EncodeToString(crypto.FromECDSA(key)), PollIntervalSecs: 1})
+	test.Nil(err)
+	now := time.Unix(1742789100, 0)
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers:  []Prover{&Sp1Prover{Client: sp1Client}},
+		JobStore: NewMemoryJobStore(),
+		Now:      func() time.Time { return now },
+	}, nil)
+	test.Nil(err)
+
+	job, err := client.SubmitProof(ctx, ZkTypeSuccinct, []byte("quote"), &Collateral{})
+	test.Nil(err)
+	test.True(job.CreatedAt.Equal(now))
+	request := network.Requests()[0]
+	test.Equal([]byte(job.RequestID), request.ID)
+	test.Equal(request.Body.VkHash, SP1_PROGRAM_VKHASH[:])
+	test.Equal(request.Body.Mode, sp1_proto.ProofMode_Groth16)
+
+	proof, err := client.ResumeProof(ctx, job)
+	test.Nil(err)
+	test.Equal(proof.Type, ZkTypeSuccinct)
+	test.Equal(proof.Mode, ProofModeGroth16)
+}
+
+func TestSp1CompressedProof(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	network := testutil.NewFakeProverNetwork(t)
+	reduceProof := testutil.SP1ReduceProof(1)
+	network.Proof = testutil.SP1CompressedProof(reduceProof, []byte("output"), "v4.0.0-rc.3")
+	key, err := crypto.GenerateKey()
+	test.Nil(err)
+	sp1Client, err := sp1.NewClient(&sp1.Config{Rpc: network.Rpc, PrivateKey: hex.EncodeToString(crypto.FromECDSA(key)), PollIntervalSecs: 1})
+	test.Nil(err)
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers:      []Prover{&Sp1Prover{Client: sp1Client}},
+		ProofModes:   map[ZkType]ProofMode{ZkTypeSuccinct: ProofModeSuccinct},
+		VerifyProofs: true,
+	}, nil)
+	test.Nil(err)
+
+	proof, err := client.ProveQuote(ctx, ZkTypeSuccinct, []byte("quote"), &Collateral{})
+	test.Nil(err)
+	test.Equal(network.Requests()[0].Body.Mode, sp1_proto.ProofMode_Compressed)
+	test.Equal(proof.Mode, ProofModeSuccinct)
+	test.Equal(proof.Output, []byte("output"))
+	test.Equal(proof.Proof, reduceProof)
+}
@@ -0,0 +1,169 @@
+package zkdcap
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ZkTypeMock is the Unknown zk coprocessor of the attestation contract.
+// Its proofs are only accepted by MockDcapAttestation of dcap-portal.
+var ZkTypeMock = ZkType(0)
+
+// MOCK_PROOF_SELECTOR is "MOCK", checked by MockDcapAttestation
+var MOCK_PROOF_SELECTOR = [4]byte{'M', 'O', 'C', 'K'}
+
+var ErrInvalidMockProof = logex.Define("invalid mock proof")
+
+// MockProver generates deterministic fake proofs without a prover service, for tests.
+// The output is derived from the quote as if it passed the verification.
+type MockProver struct {
+	// TcbStatus put into the output, defaults to TcbOK
+	TcbStatus parser.TcbStatus
+	// Fixtures maps the hex encoded SHA-256 of a quote to the output of a real proof
+	Fixtures map[string][]byte
+	// Now is used if the InputOptions has no timestamp, defaults to time.Now
+	Now func() time.Time
+}
+
+func (p *MockProver) Type() ZkType {
+	return ZkTypeMock
+}
+
+func (p *MockProver) ProgramID() string {
+	return "mock"
+}
+
+// CollateralFree reports that the collateral can be nil, it's not fetched by the portal
+func (p *MockProver) CollateralFree() bool {
+	return true
+}
+
+func (p *MockProver) Verify(proof *ZkProof) error {
+	return VerifyMockProof(proof)
+}
+
+func (p *MockProver) PrepareInput(quote []byte, collateral *Collateral, opts *InputOptions) ([]byte, error) {
+	input := NewGuestInput(quote, collateral, &InputOptions{Timestamp: opts.timestamp(p.Now)})
+	return input.Encode(), nil
+}
+
+func (p *MockProver) Prove(ctx context.Context, input []byte, mode ProofMode) (*ZkProof, error) {
+	if mode != ProofModeGroth16 {
+		return nil, ErrUnsupportedProofMode.Format(mode, p.Type())
+	}
+	guestInput, err := DecodeGuestInput(input)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+
+	quoteHash := sha256.Sum256(guestInput.Quote)
+	output, ok := p.Fixtures[hex.EncodeToString(quoteHash[:])]
+	if !ok {
+		output, err = p.output(guestInput.Timestamp, guestInput.Quote, guestInput.Collateral)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+	}
+	return &ZkProof{
+		Type:   ZkTypeMock,
+		Output: output,
+		Proof:  MockProof(output),
+	}, nil
+}
+
+// output encodes the guest output: len(2) | verified output | timestamp(8) | collateral hashes(6 * 32)
+func (p *MockProver) output(timestamp uint64, quote []byte, collateral []byte) (output []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = logex.NewErrorf("invalid quote: %v", r)
+		}
+	}()
+	if len(quote) < parser.QUOTE_HEADER_SIZE {
+		return nil, logex.NewErrorf("quote too short: %v", len(quote))
+	}
+	quoteParser := parser.NewQuoteParser(quote)
+	bodySize := parser.ENCLAVE_REPORT_SIZE
+	if quoteParser.IsTdx() {
+		bodySize = parser.TD_REPORT10_SIZE
+	}
+	if len(quote) < parser.QUOTE_HEADER_SIZE+bodySize {
+		return nil, logex.NewErrorf("quote too short: %v", len(quote))
+	}
+	certs, err := quoteParser.Certificates()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	sgxExts, err := quoteParser.SgxExt(certs[0])
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	fmspc, err := hex.DecodeString(quoteParser.Fmpsc(sgxExts))
+	if err != nil || len(fmspc) != 6 {
+		return nil, logex.NewErrorf("invalid fmspc: %x", fmspc)
+	}
+
+	var verified bytes.Buffer
+	binary.Write(&verified, binary.BigEndian, binary.LittleEndian.Uint16(quote[0:2]))
+	// the tee type is copied from the quote header
+	verified.Write(quote[4:8])
+	verified.WriteByte(byte(p.TcbStatus))
+	verified.Write(fmspc)
+	verified.Write(quote[parser.QUOTE_HEADER_SIZE : parser.QUOTE_HEADER_SIZE+bodySize])
+
+	hashes, err := mockCollateralHashes(collateral)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.BigEndian, uint16(verified.Len()))
+	out.Write(verified.Bytes())
+	binary.Write(&out, binary.BigEndian, timestamp)
+	for _, hash := range hashes {
+		out.Write(hash[:])
+	}
+	return out.Bytes(), nil
+}
+
+// mockCollateralHashes returns the keccak256 hashes of tcbInfo, qeIdentity, rootCa, signingCa,
+// rootCrl and pckCrl, the hashes are zero if the collateral is empty
+func mockCollateralHashes(collateral []byte) ([6][32]byte, error) {
+	var hashes [6][32]byte
+	if len(collateral) == 0 {
+		return hashes, nil
+	}
+	fields, err := splitCollateral(collateral)
+	if err != nil {
+		return hashes, logex.Trace(err)
+	}
+	// fields: tcbInfo, qeIdentity, rootCa, signingCa, pckCertChain, rootCrl, processorCrl, platformCrl
+	pckCrl := fields[6]
+	if len(pckCrl) == 0 {
+		pckCrl = fields[7]
+	}
+	for i, field := range [][]byte{fields[0], fields[1], fields[2], fields[3], fields[5], pckCrl} {
+		copy(hashes[i][:], crypto.Keccak256(field))
+	}
+	return hashes, nil
+}
+
+// MockProof returns the deterministic proof of the output
+func MockProof(output []byte) []byte {
+	return append(MOCK_PROOF_SELECTOR[:], crypto.Keccak256(output)...)
+}
+
+// VerifyMockProof checks the proof the same way as MockDcapAttestation
+func VerifyMockProof(proof *ZkProof) error {
+	if proof.Type != ZkTypeMock || !bytes.Equal(proof.Proof, MockProof(proof.Output)) {
+		return ErrInvalidMockProof.Trace()
+	}
+	return nil
+}
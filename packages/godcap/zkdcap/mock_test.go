@@ -0,0 +1,74 @@
+package zkdcap
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
+	"github.com/chzyer/test"
+)
+
+// journal of a RISC Zero proof for mock.Quotes[1]
+//
+//go:embed test_zkoutput.hex
+var testZkOutput string
+
+func TestMockProver(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	now := time.Unix(1742789100, 0)
+	prover := new(MockProver)
+	client, err := NewZkProofClient(&ZkProofConfig{Provers: []Prover{prover}, Now: func() time.Time { return now }}, nil)
+	test.Nil(err)
+
+	fmspcs := []string{"00606a000000", "90c06f000000"}
+	for i, quote := range mock.Quotes {
+		proof, err := client.ProveQuote(ctx, ZkTypeMock, quote, nil)
+		test.Nil(err)
+		test.Nil(VerifyMockProof(proof))
+
+		output, err := proof.VerifiedOutput()
+		test.Nil(err)
+		test.Equal(output.TcbStatus, parser.TcbOK)
+		test.Equal(output.FmspcHex(), fmspcs[i])
+		test.True(bytes.Equal(output.QuoteBody, quote[parser.QUOTE_HEADER_SIZE:parser.QUOTE_HEADER_SIZE+len(output.QuoteBody)]))
+
+		again, err := client.ProveQuote(ctx, ZkTypeMock, quote, nil)
+		test.Nil(err)
+		test.Equal(again, proof)
+
+		proof.Output[len(proof.Output)-1] ^= 1
+		test.NotNil(VerifyMockProof(proof))
+	}
+	test.True(!NeedsCollateral(prover))
+}
+
+func TestMockProverOutput(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	journal, err := hex.DecodeString(testZkOutput)
+	test.Nil(err)
+	verifiedOutputSize := 2 + 13 + parser.TD_REPORT10_SIZE
+
+	// the derived output has the layout of the real guest
+	prover := &MockProver{TcbStatus: parser.TcbStatus(journal[8])}
+	input, err := prover.PrepareInput(mock.Quotes[1], nil, nil)
+	test.Nil(err)
+	proof, err := prover.Prove(ctx, input, ProofModeGroth16)
+	test.Nil(err)
+	test.Equal(len(proof.Output), len(journal))
+	test.Equal(proof.Output[:verifiedOutputSize], journal[:verifiedOutputSize])
+
+	quoteHash := sha256.Sum256(mock.Quotes[1])
+	prover.Fixtures = map[string][]byte{hex.EncodeToString(quoteHash[:]): journal}
+	proof, err = prover.Prove(ctx, input, ProofModeGroth16)
+	test.Nil(err)
+	test.Equal(proof.Output, journal)
+	test.Nil(VerifyMockProof(proof))
+}
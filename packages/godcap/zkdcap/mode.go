@@ -0,0 +1,86 @@
+package zkdcap
+
+import (
+	"github.com/chzyer/logex"
+)
+
+var (
+	ErrUnsupportedProofMode = logex.Define("proof mode %v is not supported by zkType %v")
+	ErrProofModeNotOnChain  = logex.Define("proof mode %v can't be verified on chain")
+	ErrUnverifiableMode     = logex.Define("proof mode %v of zkType %v can't be verified off-chain")
+)
+
+// ProofMode selects the kind of proof generated by a prover
+type ProofMode uint8
+
+const (
+	// ProofModeGroth16 is the default mode, accepted by the on-chain verifiers
+	ProofModeGroth16 ProofMode = iota
+	// ProofModePlonk is accepted by the on-chain verifiers of sp1
+	ProofModePlonk
+	// ProofModeSuccinct is the compressed STARK proof, the succinct receipt of risc0 or the reduce proof of sp1, for off-chain use or recursion
+	ProofModeSuccinct
+)
+
+var proofModeNames = map[ProofMode]string{
+	ProofModeGroth16:  "groth16",
+	ProofModePlonk:    "plonk",
+	ProofModeSuccinct: "succinct",
+}
+
+func (m ProofMode) String() string {
+	if name, ok := proofModeNames[m]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseProofMode parses the name returned by ProofMode.String
+func ParseProofMode(name string) (ProofMode, error) {
+	for mode, modeName := range proofModeNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+	return 0, logex.NewErrorf("unknown proof mode: %q", name)
+}
+
+func (m ProofMode) MarshalText() ([]byte, error) {
+	if _, ok := proofModeNames[m]; !ok {
+		return nil, logex.NewErrorf("unknown proof mode: %d", m)
+	}
+	return []byte(m.String()), nil
+}
+
+func (m *ProofMode) UnmarshalText(data []byte) error {
+	mode, err := ParseProofMode(string(data))
+	if err != nil {
+		return logex.Trace(err)
+	}
+	*m = mode
+	return nil
+}
+
+// OnChain reports whether the proofs of the mode can be submitted to the portal
+func (m ProofMode) OnChain() bool {
+	return m == ProofModeGroth16 || m == ProofModePlonk
+}
+
+// ModeProver is implemented by provers supporting other modes than ProofModeGroth16
+type ModeProver interface {
+	ProofModes() []ProofMode
+}
+
+// SupportsMode reports whether the prover can generate proofs of the mode
+func SupportsMode(prover Prover, mode ProofMode) bool {
+	p, ok := prover.(ModeProver)
+	if !ok {
+		return mode == ProofModeGroth16
+	}
+	for _, m := range p.ProofModes() {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
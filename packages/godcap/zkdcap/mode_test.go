@@ -0,0 +1,77 @@
+package zkdcap
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bonsai"
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/automata-network/dcap-sdk/packages/godcap/testutil"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+)
+
+func TestProofModeJSON(t *testing.T) {
+	defer test.New(t)
+	var cfg ZkProofConfig
+	test.Nil(json.Unmarshal([]byte(`{"proof_modes": {"1": "succinct", "2": "plonk"}}`), &cfg))
+	test.Equal(cfg.ProofModes, map[ZkType]ProofMode{ZkTypeRiscZero: ProofModeSuccinct, ZkTypeSuccinct: ProofModePlonk})
+
+	data, err := json.Marshal(&ProofCacheKey{Mode: ProofModePlonk})
+	test.Nil(err)
+	var key ProofCacheKey
+	test.Nil(json.Unmarshal(data, &key))
+	test.Equal(key.Mode, ProofModePlonk)
+
+	test.NotNil(json.Unmarshal([]byte(`{"proof_modes": {"1": "stark"}}`), &cfg))
+	_, err = ProofMode(0xff).MarshalText()
+	test.NotNil(err)
+	test.True(ProofModeGroth16.OnChain() && ProofModePlonk.OnChain())
+	test.True(!ProofModeSuccinct.OnChain())
+}
+
+func TestSupportsMode(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	test.True(SupportsMode(new(MockProver), ProofModeGroth16))
+	test.True(!SupportsMode(new(MockProver), ProofModePlonk))
+	test.True(SupportsMode(new(BonsaiProver), ProofModeSuccinct))
+	test.True(!SupportsMode(new(BonsaiProver), ProofModePlonk))
+	test.True(SupportsMode(new(Sp1Prover), ProofModePlonk))
+	test.True(SupportsMode(new(Sp1Prover), ProofModeSuccinct))
+
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers:    []Prover{new(MockProver)},
+		ProofModes: map[ZkType]ProofMode{ZkTypeMock: ProofModePlonk},
+	}, nil)
+	test.Nil(err)
+	_, err = client.ProveQuote(ctx, ZkTypeMock, mock.Quotes[0], nil)
+	test.True(logex.Equal(err, ErrUnsupportedProofMode))
+	_, err = new(MockProver).Prove(ctx, nil, ProofModeSuccinct)
+	test.True(logex.Equal(err, ErrUnsupportedProofMode))
+}
+
+func TestSuccinctProof(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	server := testutil.NewFakeBonsai(t)
+
+	bonsaiClient, err := bonsai.NewClient(&bonsai.Config{Url: server.URL, ApiKey: "key", PollIntervalSecs: 1})
+	test.Nil(err)
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers:    []Prover{&BonsaiProver{Client: bonsaiClient, ImageID: testutil.BONSAI_RECEIPT_IMAGE_ID}},
+		ProofModes: map[ZkType]ProofMode{ZkTypeRiscZero: ProofModeSuccinct},
+	}, nil)
+	test.Nil(err)
+	proof, err := client.ProveQuote(ctx, ZkTypeRiscZero, []byte("quote"), &Collateral{})
+	test.Nil(err)
+	test.Equal(proof.Mode, ProofModeSuccinct)
+	test.Equal(proof.Proof, server.SessionReceipt)
+
+	receipt, err := bonsai.NewReceiptFromBincode(proof.Proof)
+	test.Nil(err)
+	test.Equal(proof.Output, []byte(receipt.Journal.Bytes))
+	// succinct receipts are not accepted on chain
+	test.True(!proof.Mode.OnChain())
+}
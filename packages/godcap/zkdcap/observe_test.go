@@ -0,0 +1,44 @@
+package zkdcap
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bonsai"
+	"github.com/automata-network/dcap-sdk/packages/godcap/observe"
+	"github.com/automata-network/dcap-sdk/packages/godcap/testutil"
+	"github.com/chzyer/test"
+)
+
+func TestProofEvents(t *testing.T) {
+	defer test.New(t)
+	server := testutil.NewFakeBonsai(t)
+
+	var events []string
+	observer := observe.ObserverFunc(func(ev *observe.Event) {
+		test.False(ev.Time.IsZero())
+		events = append(events, fmt.Sprintf("%v %v %v %v", ev.Source, ev.Kind, ev.ID, ev.State))
+	})
+	bonsaiClient, err := bonsai.NewClient(&bonsai.Config{Url: server.URL, ApiKey: "key", PollIntervalSecs: 1, Observer: observer})
+	test.Nil(err)
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers:  []Prover{&BonsaiProver{Client: bonsaiClient, ImageID: testutil.BONSAI_RECEIPT_IMAGE_ID}},
+		Observer: observer,
+	}, nil)
+	test.Nil(err)
+	proof, err := client.ProveQuote(context.Background(), ZkTypeRiscZero, []byte("quote"), &Collateral{})
+	test.Nil(err)
+
+	test.Equal(events, []string{
+		"bonsai input_uploaded input-1 ",
+		"bonsai session_created session-1 ",
+		"bonsai state_changed session-1 SUCCEEDED",
+		"bonsai cycles session-1 ",
+		"bonsai fulfilled session-1 ",
+		"bonsai snark_started snark-1 ",
+		"bonsai state_changed snark-1 SUCCEEDED",
+		"bonsai fulfilled snark-1 ",
+		fmt.Sprintf("zkdcap fulfilled %x ", proof.InputDigest),
+	})
+}
@@ -0,0 +1,120 @@
+package zkdcap
+
+import (
+	"encoding/hex"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrUnknownProgram = logex.Define("unknown program version: %q")
+
+// DEFAULT_PROGRAM_VERSION is the version of the guest embedded in godcap
+const DEFAULT_PROGRAM_VERSION = "builtin"
+
+// Program is a version of the dcap guest, built for each zkvm
+type Program struct {
+	Version string `json:"version"`
+	// ImageID of the risc0 guest in hex without 0x, the risc0 prover is disabled if empty
+	ImageID string `json:"image_id"`
+	// ElfPath loads the risc0 guest from the disk if Elf is nil
+	ElfPath string `json:"elf_path"`
+	Elf     []byte `json:"-"`
+	// Sp1VkHash of the sp1 guest registered on the network, the sp1 prover is disabled if zero
+	Sp1VkHash common.Hash `json:"sp1_vkhash"`
+}
+
+// DefaultProgram returns the guest embedded in godcap
+func DefaultProgram() *Program {
+	return &Program{
+		Version:   DEFAULT_PROGRAM_VERSION,
+		ImageID:   BONSAI_IMAGE_ID,
+		Elf:       BONSAI_DCAP_GUEST_ELF,
+		Sp1VkHash: SP1_PROGRAM_VKHASH,
+	}
+}
+
+// LoadElf returns the risc0 guest, it's read from ElfPath once
+func (p *Program) LoadElf() ([]byte, error) {
+	if p.Elf != nil {
+		return p.Elf, nil
+	}
+	if p.ElfPath == "" {
+		return nil, logex.NewErrorf("program %q has no elf", p.Version)
+	}
+	elf, err := os.ReadFile(p.ElfPath)
+	if err != nil {
+		return nil, logex.Trace(err, p.Version)
+	}
+	p.Elf = elf
+	return elf, nil
+}
+
+// ProgramID returns the identifier of the guest for the ZkType, "" if it isn't built for it
+func (p *Program) ProgramID(ty ZkType) string {
+	switch ty {
+	case ZkTypeRiscZero:
+		return p.ImageID
+	case ZkTypeSuccinct:
+		if p.Sp1VkHash == (common.Hash{}) {
+			return ""
+		}
+		return p.Sp1VkHash.Hex()
+	}
+	return ""
+}
+
+func (p *Program) validate() error {
+	if p.Version == "" {
+		return logex.NewError("program version is required")
+	}
+	// the image id is passed to bonsai as is, without 0x like BONSAI_IMAGE_ID
+	if p.ImageID != "" {
+		if id, err := hex.DecodeString(p.ImageID); err != nil || len(id) != common.HashLength {
+			return logex.NewErrorf("invalid image id of program %q: %v", p.Version, p.ImageID)
+		}
+	}
+	return nil
+}
+
+var programRegistry = struct {
+	sync.RWMutex
+	programs map[string]*Program
+}{programs: map[string]*Program{DEFAULT_PROGRAM_VERSION: DefaultProgram()}}
+
+// RegisterProgram registers a version of the guest, replacing the previous one
+func RegisterProgram(program *Program) error {
+	if err := program.validate(); err != nil {
+		return logex.Trace(err)
+	}
+	programRegistry.Lock()
+	defer programRegistry.Unlock()
+	programRegistry.programs[program.Version] = program
+	return nil
+}
+
+// LookupProgram returns the registered program of the version
+func LookupProgram(version string) (*Program, error) {
+	programRegistry.RLock()
+	defer programRegistry.RUnlock()
+	program, ok := programRegistry.programs[version]
+	if !ok {
+		return nil, ErrUnknownProgram.Format(version)
+	}
+	return program, nil
+}
+
+// RegisteredPrograms returns the versions of the registered programs
+func RegisteredPrograms() []string {
+	programRegistry.RLock()
+	defer programRegistry.RUnlock()
+	versions := make([]string, 0, len(programRegistry.programs))
+	for version := range programRegistry.programs {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
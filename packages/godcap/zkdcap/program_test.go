@@ -0,0 +1,64 @@
+package zkdcap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProgramRegistry(t *testing.T) {
+	defer test.New(t)
+	client, err := NewZkProofClient(nil, nil)
+	test.Nil(err)
+	prover, err := client.Prover(ZkTypeRiscZero)
+	test.Nil(err)
+	test.Equal(prover.ProgramID(), BONSAI_IMAGE_ID)
+	prover, err = client.Prover(ZkTypeSuccinct)
+	test.Nil(err)
+	test.Equal(prover.ProgramID(), SP1_PROGRAM_VKHASH.Hex())
+
+	elfPath := filepath.Join(t.TempDir(), "guest.elf")
+	test.Nil(os.WriteFile(elfPath, []byte("elf"), 0644))
+	imageId := "83613a8beec226d1f29714530f1df791fa16c2c4dfcf22c50ab7edac59ca637f"
+	test.Nil(RegisterProgram(&Program{Version: "test-risc0", ImageID: imageId, ElfPath: elfPath}))
+	test.Equal(RegisteredPrograms(), []string{DEFAULT_PROGRAM_VERSION, "test-risc0"})
+
+	// the program isn't built for sp1
+	client, err = NewZkProofClient(&ZkProofConfig{ProgramVersion: "test-risc0"}, nil)
+	test.Nil(err)
+	prover, err = client.Prover(ZkTypeRiscZero)
+	test.Nil(err)
+	test.Equal(prover.ProgramID(), imageId)
+	test.Equal(prover.(*BonsaiProver).Elf, []byte("elf"))
+	_, err = client.Prover(ZkTypeSuccinct)
+	test.NotNil(err)
+
+	// the programs of the config take precedence
+	vkHash := common.Hash{1}
+	client, err = NewZkProofClient(&ZkProofConfig{
+		ProgramVersion: "test-risc0",
+		Programs:       []*Program{{Version: "test-risc0", Sp1VkHash: vkHash}},
+	}, nil)
+	test.Nil(err)
+	prover, err = client.Prover(ZkTypeSuccinct)
+	test.Nil(err)
+	test.Equal(prover.ProgramID(), vkHash.Hex())
+	_, err = client.Prover(ZkTypeRiscZero)
+	test.NotNil(err)
+
+	_, err = NewZkProofClient(&ZkProofConfig{ProgramVersion: "unknown"}, nil)
+	test.True(logex.Equal(err, ErrUnknownProgram))
+	test.NotNil(RegisterProgram(&Program{Version: "bad", ImageID: "1234"}))
+	test.NotNil(RegisterProgram(&Program{Version: "bad", ImageID: "0x" + imageId}))
+	_, err = NewZkProofClient(&ZkProofConfig{
+		ProgramVersion: "prefixed",
+		Programs:       []*Program{{Version: "prefixed", ImageID: "0x" + imageId}},
+	}, nil)
+	test.NotNil(err)
+	_, err = (&Program{Version: "no-elf", ImageID: imageId}).LoadElf()
+	test.NotNil(err)
+}
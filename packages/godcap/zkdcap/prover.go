@@ -0,0 +1,122 @@
+package zkdcap
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/chzyer/logex"
+)
+
+// Prover is a zk backend proving the DCAP verification of a quote
+type Prover interface {
+	// Type is submitted to the portal to select the on-chain verifier
+	Type() ZkType
+	// ProgramID identifies the guest program, e.g. the image id or the vkhash
+	ProgramID() string
+	// PrepareInput encodes the guest input for the quote and its collateral, opts can be nil
+	PrepareInput(quote []byte, collateral *Collateral, opts *InputOptions) ([]byte, error)
+	// Prove runs the guest with the input, the output and proof are encoded for the portal.
+	// The mode is one of the modes reported by SupportsMode.
+	Prove(ctx context.Context, input []byte, mode ProofMode) (*ZkProof, error)
+}
+
+// CollateralFreeProver is implemented by provers which accept a nil collateral, e.g. MockProver
+type CollateralFreeProver interface {
+	CollateralFree() bool
+}
+
+// VerifyingProver is implemented by provers which can verify their proofs off-chain
+type VerifyingProver interface {
+	Verify(proof *ZkProof) error
+}
+
+// ModeVerifier is implemented by VerifyingProvers which can't verify the proofs of every mode they generate
+type ModeVerifier interface {
+	VerifiesMode(mode ProofMode) bool
+}
+
+// VerifiesMode reports whether the off-chain verification of the prover supports the mode
+func VerifiesMode(prover Prover, mode ProofMode) bool {
+	if _, ok := prover.(VerifyingProver); !ok {
+		return false
+	}
+	if p, ok := prover.(ModeVerifier); ok {
+		return p.VerifiesMode(mode)
+	}
+	return true
+}
+
+// NeedsCollateral reports whether the collateral of the quote should be fetched for the prover
+func NeedsCollateral(prover Prover) bool {
+	p, ok := prover.(CollateralFreeProver)
+	return !ok || !p.CollateralFree()
+}
+
+// ProverFactory creates a prover from the config of the ZkProofClient
+type ProverFactory func(cfg *ZkProofConfig) (Prover, error)
+
+var proverRegistry = struct {
+	sync.RWMutex
+	factories map[ZkType]ProverFactory
+}{factories: make(map[ZkType]ProverFactory)}
+
+// RegisterProver registers the prover factory of a ZkType, replacing the previous one
+func RegisterProver(ty ZkType, factory ProverFactory) {
+	proverRegistry.Lock()
+	defer proverRegistry.Unlock()
+	proverRegistry.factories[ty] = factory
+}
+
+// RegisteredProvers returns the ZkTypes having a registered prover factory
+func RegisteredProvers() []ZkType {
+	proverRegistry.RLock()
+	defer proverRegistry.RUnlock()
+	types := make([]ZkType, 0, len(proverRegistry.factories))
+	for ty := range proverRegistry.factories {
+		types = append(types, ty)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// knownZkType reports whether the type is ZkTypeMock or has a registered prover factory
+func knownZkType(ty ZkType) bool {
+	if ty == ZkTypeMock {
+		return true
+	}
+	proverRegistry.RLock()
+	defer proverRegistry.RUnlock()
+	_, ok := proverRegistry.factories[ty]
+	return ok
+}
+
+func init() {
+	RegisterProver(ZkTypeRiscZero, NewBonsaiProver)
+	RegisterProver(ZkTypeSuccinct, NewSp1Prover)
+}
+
+// newProvers creates the provers of all the registered factories, the provers in cfg take precedence
+func newProvers(cfg *ZkProofConfig) (map[ZkType]Prover, error) {
+	proverRegistry.RLock()
+	factories := make(map[ZkType]ProverFactory, len(proverRegistry.factories))
+	for ty, factory := range proverRegistry.factories {
+		factories[ty] = factory
+	}
+	proverRegistry.RUnlock()
+
+	provers := make(map[ZkType]Prover, len(factories))
+	for ty, factory := range factories {
+		prover, err := factory(cfg)
+		if err != nil {
+			return nil, logex.Trace(err, ty)
+		}
+		if prover != nil {
+			provers[ty] = prover
+		}
+	}
+	for _, prover := range cfg.Provers {
+		provers[prover.Type()] = prover
+	}
+	return provers, nil
+}
@@ -0,0 +1,65 @@
+package zkdcap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bonsai"
+	"github.com/chzyer/test"
+)
+
+type echoProver struct{}
+
+func (echoProver) Type() ZkType { return ZkType(0xff) }
+
+func (echoProver) ProgramID() string { return "echo" }
+
+func (echoProver) PrepareInput(quote []byte, collateral *Collateral, opts *InputOptions) ([]byte, error) {
+	return quote, nil
+}
+
+func (p echoProver) Prove(ctx context.Context, input []byte, mode ProofMode) (*ZkProof, error) {
+	return &ZkProof{Type: p.Type(), Output: input}, nil
+}
+
+func TestProverRegistry(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	test.Equal(RegisteredProvers(), []ZkType{ZkTypeRiscZero, ZkTypeSuccinct})
+
+	client, err := NewZkProofClient(&ZkProofConfig{Provers: []Prover{echoProver{}}}, nil)
+	test.Nil(err)
+	proof, err := client.ProveQuote(ctx, ZkType(0xff), []byte("quote"), nil)
+	test.Nil(err)
+	test.Equal(proof.Output, []byte("quote"))
+
+	_, err = client.ProveQuote(ctx, ZkType(0xfe), nil, nil)
+	test.NotNil(err)
+
+	RegisterProver(ZkType(0xfe), func(cfg *ZkProofConfig) (Prover, error) {
+		return echoProver{}, nil
+	})
+	defer func() {
+		proverRegistry.Lock()
+		delete(proverRegistry.factories, ZkType(0xfe))
+		proverRegistry.Unlock()
+	}()
+	client, err = NewZkProofClient(nil, nil)
+	test.Nil(err)
+	_, err = client.Prover(ZkType(0xfe))
+	test.Nil(err)
+}
+
+func TestSharedConfig(t *testing.T) {
+	defer test.New(t)
+	cfg := &ZkProofConfig{Bonsai: &bonsai.Config{ApiKey: "key"}}
+	for i := 0; i < 2; i++ {
+		client, err := NewZkProofClient(cfg, nil)
+		test.Nil(err)
+		_, err = client.Prover(ZkTypeRiscZero)
+		test.Nil(err)
+	}
+	// the defaults of the clients aren't written back
+	test.Equal(*cfg.Bonsai, bonsai.Config{ApiKey: "key"})
+	test.True(cfg.Sp1 == nil)
+}
@@ -1,10 +1,14 @@
 package zkdcap
 
 import (
+	"context"
 	_ "embed"
-	"encoding/binary"
-	"time"
+	"encoding/hex"
+	"sync"
 
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1"
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1/sp1_proto"
+	"github.com/chzyer/logex"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -21,24 +25,195 @@ import (
 // ```
 var SP1_PROGRAM_VKHASH = common.HexToHash("0x0036efd519bb371b29a40322e40031833716e9441c6907f8aefc5e52ceebc9a6")
 
+// Sp1GenerateInput encodes the guest input verified at the current time
 func Sp1GenerateInput(quote []byte, collateral *Collateral) []byte {
-	collateralBytes := collateral.Encode()
-	currentTime := uint64(time.Now().Unix())
-	totalLen := 8 + 4 + 4 + len(quote) + len(collateralBytes)
-	data := make([]byte, 0, totalLen)
+	return NewGuestInput(quote, collateral, nil).Encode()
+}
+
+// Sp1Prover proves the guest on the Succinct prover network
+type Sp1Prover struct {
+	Client *sp1.Client
+	// VkHash of the guest, defaults to SP1_PROGRAM_VKHASH
+	VkHash common.Hash
+
+	cfg  *sp1.Config
+	mu   sync.Mutex
+	keys *sp1.VerifierKeys
+}
+
+// NewSp1Prover is the ProverFactory of ZkTypeSuccinct
+func NewSp1Prover(cfg *ZkProofConfig) (Prover, error) {
+	// cfg.Sp1 is left untouched, it may be shared by other clients
+	sp1Cfg := new(sp1.Config)
+	if cfg.Sp1 != nil {
+		*sp1Cfg = *cfg.Sp1
+	}
+	if sp1Cfg.Observer == nil {
+		sp1Cfg.Observer = cfg.Observer
+	}
+	if err := sp1Cfg.Init(); err != nil {
+		return nil, logex.Trace(err)
+	}
+	program, err := cfg.Program()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if program.Sp1VkHash == (common.Hash{}) {
+		// the program isn't built for sp1
+		return nil, nil
+	}
+	prover := &Sp1Prover{VkHash: program.Sp1VkHash, cfg: sp1Cfg}
+	if sp1Cfg.PrivateKey != "" {
+		client, err := sp1.NewClient(sp1Cfg)
+		if err != nil {
+			return nil, logex.Trace(err)
+		}
+		prover.Client = client
+	}
+	return prover, nil
+}
+
+func (p *Sp1Prover) Type() ZkType {
+	return ZkTypeSuccinct
+}
+
+func (p *Sp1Prover) ProgramID() string {
+	return p.vkHash().Hex()
+}
+
+func (p *Sp1Prover) vkHash() common.Hash {
+	if p.VkHash == (common.Hash{}) {
+		return SP1_PROGRAM_VKHASH
+	}
+	return p.VkHash
+}
+
+// VerifierKeys loads the verifying keys of the circuits from sp1.Config.CircuitsDir once
+func (p *Sp1Prover) VerifierKeys() (*sp1.VerifierKeys, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys != nil {
+		return p.keys, nil
+	}
+	if p.cfg == nil {
+		return nil, logex.NewError("sp1 config is required")
+	}
+	keys, err := sp1.LoadVerifierKeys(p.cfg.CircuitsDir, p.cfg.Version)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	p.keys = keys
+	return keys, nil
+}
 
-	var buf [8]byte
-	binary.LittleEndian.PutUint64(buf[:], currentTime)
-	data = append(data, buf[:]...)
+// SetVerifierKeys overrides the verifying keys loaded from the circuits directory
+func (p *Sp1Prover) SetVerifierKeys(keys *sp1.VerifierKeys) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = keys
+}
+
+// Verify verifies the Groth16 or Plonk proof off-chain with the keys of the configured sp1 version
+func (p *Sp1Prover) Verify(proof *ZkProof) error {
+	keys, err := p.VerifierKeys()
+	if err != nil {
+		return logex.Trace(err)
+	}
+	return VerifySp1Proof(proof, p.vkHash(), keys)
+}
+
+// VerifiesMode reports whether Verify supports the mode, the compressed proofs are verified by the recursion only
+func (p *Sp1Prover) VerifiesMode(mode ProofMode) bool {
+	return mode == ProofModeGroth16 || mode == ProofModePlonk
+}
+
+// ProofModes are the modes verified by the sp1 gateway on chain, and the compressed proof for recursion
+func (p *Sp1Prover) ProofModes() []ProofMode {
+	return []ProofMode{ProofModeGroth16, ProofModePlonk, ProofModeSuccinct}
+}
 
-	binary.LittleEndian.PutUint32(buf[:4], uint32(len(quote)))
-	data = append(data, buf[:4]...)
+// sp1ProofModes maps the modes to the network modes
+var sp1ProofModes = map[ProofMode]sp1_proto.ProofMode{
+	ProofModeGroth16:  sp1_proto.ProofMode_Groth16,
+	ProofModePlonk:    sp1_proto.ProofMode_Plonk,
+	ProofModeSuccinct: sp1_proto.ProofMode_Compressed,
+}
+
+func (p *Sp1Prover) PrepareInput(quote []byte, collateral *Collateral, opts *InputOptions) ([]byte, error) {
+	return NewGuestInput(quote, collateral, opts).Encode(), nil
+}
 
-	binary.LittleEndian.PutUint32(buf[:4], uint32(len(collateralBytes)))
-	data = append(data, buf[:4]...)
+func (p *Sp1Prover) Prove(ctx context.Context, input []byte, mode ProofMode) (*ZkProof, error) {
+	job, err := p.Submit(ctx, input, mode)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return p.Resume(ctx, job, nil)
+}
 
-	data = append(data, quote...)
+// Submit uploads the stdin and requests the proof on the network
+func (p *Sp1Prover) Submit(ctx context.Context, input []byte, mode ProofMode) (*ProofJob, error) {
+	if p.Client == nil {
+		return nil, logex.NewError("NETWORK_PRIVATE_KEY is required")
+	}
+	networkMode, ok := sp1ProofModes[mode]
+	if !ok {
+		return nil, ErrUnsupportedProofMode.Format(mode, p.Type())
+	}
+	requestId, err := p.Client.CreateProof(ctx, p.vkHash(), sp1.NewSP1StdinFromInput(input), networkMode)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	job := NewProofJob(ZkTypeSuccinct, mode, input)
+	job.ID = hex.EncodeToString(requestId)
+	job.RequestID = requestId
+	return job, nil
+}
+
+// Resume polls the request until it's fulfilled
+func (p *Sp1Prover) Resume(ctx context.Context, job *ProofJob, checkpoint func(*ProofJob) error) (*ZkProof, error) {
+	if p.Client == nil {
+		return nil, logex.NewError("NETWORK_PRIVATE_KEY is required")
+	}
+	if job.Type != ZkTypeSuccinct || len(job.RequestID) == 0 {
+		return nil, logex.NewErrorf("not a sp1 job: %v", job.ID)
+	}
+	res, err := p.Client.PollProof(ctx, job.RequestID, p.Client.PollInterval())
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	proof, err := Sp1ProofFromNetwork(res)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if proof.Mode != job.Mode {
+		return nil, logex.NewErrorf("proof mode mismatch: want %v, got %v", job.Mode, proof.Mode)
+	}
+	return proof, nil
+}
 
-	data = append(data, collateralBytes...)
-	return data
+// Sp1ProofFromNetwork encodes the proof downloaded from the network for the portal,
+// the compressed proof is the bincode of the reduce proof
+func Sp1ProofFromNetwork(res *sp1.SP1ProofWithPublicValues) (*ZkProof, error) {
+	var mode ProofMode
+	switch res.Proof.Type.Raw() {
+	case sp1.PROOF_TYPE_GROTH16:
+		mode = ProofModeGroth16
+	case sp1.PROOF_TYPE_PLONK:
+		mode = ProofModePlonk
+	case sp1.PROOF_TYPE_COMPRESSED:
+		mode = ProofModeSuccinct
+	default:
+		return nil, logex.NewErrorf("unsupported sp1 proof: %v", res.Proof.Type.Raw())
+	}
+	proofBytes, err := res.Bytes()
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	return &ZkProof{
+		Type:   ZkTypeSuccinct,
+		Mode:   mode,
+		Output: []byte(res.PublicValues.Buffer.Data),
+		Proof:  proofBytes,
+	}, nil
 }
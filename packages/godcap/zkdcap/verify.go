@@ -0,0 +1,48 @@
+package zkdcap
+
+import (
+	"bytes"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bonsai"
+	"github.com/automata-network/dcap-sdk/packages/godcap/groth16"
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1"
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// VerifyRiscZeroProof verifies the Groth16 seal of the proof proves the execution of the image with the output
+func VerifyRiscZeroProof(proof *ZkProof, imageId string) error {
+	if proof.Type != ZkTypeRiscZero {
+		return logex.NewErrorf("not a risc0 proof: %v", proof.Type)
+	}
+	if proof.Mode != ProofModeGroth16 {
+		return logex.NewErrorf("off-chain verification is not supported for %v proofs", proof.Mode)
+	}
+	id, err := bonsai.DigestFromHex(imageId)
+	if err != nil {
+		return logex.Trace(err, "imageId")
+	}
+	if len(proof.Proof) < 4 {
+		return groth16.ErrInvalidProof.Trace(len(proof.Proof))
+	}
+	params := bonsai.GROTH16_VERIFIER_PARAMETERS
+	selector := params.Selector()
+	if !bytes.Equal(proof.Proof[:4], selector[:]) {
+		return bonsai.ErrSelectorMismatch.Format(selector, proof.Proof[:4])
+	}
+	if err := bonsai.VerifyGroth16Journal(proof.Proof[4:], id, proof.Output, params); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+// VerifySp1Proof verifies the Groth16 proof of the program with the output as the public values
+func VerifySp1Proof(proof *ZkProof, vkey common.Hash, keys *sp1.VerifierKeys) error {
+	if proof.Type != ZkTypeSuccinct {
+		return logex.NewErrorf("not a sp1 proof: %v", proof.Type)
+	}
+	if err := keys.VerifyBytes(vkey, proof.Output, proof.Proof); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package zkdcap
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/automata-network/dcap-sdk/packages/godcap/bonsai"
+	"github.com/automata-network/dcap-sdk/packages/godcap/groth16"
+	"github.com/automata-network/dcap-sdk/packages/godcap/mock"
+	"github.com/automata-network/dcap-sdk/packages/godcap/plonk"
+	"github.com/automata-network/dcap-sdk/packages/godcap/sp1"
+	"github.com/automata-network/dcap-sdk/packages/godcap/testutil"
+	"github.com/chzyer/logex"
+	"github.com/chzyer/test"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func testGroth16Receipt() *bonsai.Receipt {
+	data, err := testutil.ReadBonsaiReceipt("test_receipt_2.hex")
+	test.Nil(err)
+	receipt, err := bonsai.NewReceiptFromBincode(data)
+	test.Nil(err)
+	return receipt
+}
+
+func TestRiscZeroProofFromReceipt(t *testing.T) {
+	defer test.New(t)
+	receipt := testGroth16Receipt()
+	imageId, err := (*receipt.Inner.Groth16.Claim.Value).ImageID()
+	test.Nil(err)
+
+	proof, err := RiscZeroProofFromReceipt(receipt, imageId.Hex())
+	test.Nil(err)
+	test.Equal(proof.Output, []byte(receipt.Journal.Bytes))
+	test.Equal(proof.Proof[:4], []byte{0x50, 0xbd, 0x17, 0x69})
+
+	// the receipt was proved by a previous image
+	_, err = RiscZeroProofFromReceipt(receipt, BONSAI_IMAGE_ID)
+	test.True(logex.Equal(err, bonsai.ErrImageIdMismatch))
+	receipt.Journal.Bytes = receipt.Journal.Bytes[1:]
+	_, err = RiscZeroProofFromReceipt(receipt, imageId.Hex())
+	test.True(logex.Equal(err, bonsai.ErrJournalMismatch))
+}
+
+func TestVerifyRiscZeroProof(t *testing.T) {
+	defer test.New(t)
+	receipt := testGroth16Receipt()
+
+	var selector [4]byte
+	binary.LittleEndian.PutUint32(selector[:], receipt.Inner.Groth16.VerifierParameters[0])
+	proof := &ZkProof{
+		Type:   ZkTypeRiscZero,
+		Output: receipt.Journal.Bytes,
+		Proof:  bonsai.Groth16Encode(selector, receipt.Inner.Groth16.Seal),
+	}
+	test.Nil(VerifyRiscZeroProof(proof, testutil.BONSAI_RECEIPT_IMAGE_ID))
+	// the receipt was proved by a previous image
+	test.True(logex.Equal(VerifyRiscZeroProof(proof, BONSAI_IMAGE_ID), groth16.ErrVerification))
+
+	proof.Proof[0] ^= 1
+	test.True(logex.Equal(VerifyRiscZeroProof(proof, BONSAI_IMAGE_ID), bonsai.ErrSelectorMismatch))
+	proof.Proof = proof.Proof[:3]
+	test.True(logex.Equal(VerifyRiscZeroProof(proof, BONSAI_IMAGE_ID), groth16.ErrInvalidProof))
+}
+
+func TestVerifyProofs(t *testing.T) {
+	defer test.New(t)
+	ctx := context.Background()
+	provers := []Prover{echoProver{}, new(MockProver)}
+	client, err := NewZkProofClient(&ZkProofConfig{Provers: provers, VerifyProofs: true}, nil)
+	test.Nil(err)
+
+	_, err = client.ProveQuote(ctx, ZkTypeMock, mock.Quotes[0], nil)
+	test.Nil(err)
+	// echo proofs can't be verified
+	_, err = client.ProveQuote(ctx, ZkType(0xff), mock.Quotes[0], nil)
+	test.NotNil(err)
+
+	client, err = NewZkProofClient(&ZkProofConfig{Provers: provers}, nil)
+	test.Nil(err)
+	_, err = client.ProveQuote(ctx, ZkType(0xff), mock.Quotes[0], nil)
+	test.Nil(err)
+}
+
+func TestVerifyBonsaiProofs(t *testing.T) {
+	defer test.New(t)
+	server := testutil.NewFakeBonsai(t)
+	bonsaiClient, err := bonsai.NewClient(&bonsai.Config{Url: server.URL, ApiKey: "key", PollIntervalSecs: 1})
+	test.Nil(err)
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers:      []Prover{&BonsaiProver{Client: bonsaiClient, ImageID: testutil.BONSAI_RECEIPT_IMAGE_ID}},
+		VerifyProofs: true,
+	}, nil)
+	test.Nil(err)
+	// the snark receipt of the fake is a real Bonsai proof
+	proof, err := client.ProveQuote(context.Background(), ZkTypeRiscZero, []byte("quote"), &Collateral{})
+	test.Nil(err)
+	test.Equal(proof.Mode, ProofModeGroth16)
+}
+
+func TestVerifySp1Proof(t *testing.T) {
+	defer test.New(t)
+	vk, td, err := testutil.NewGroth16TestKey(2)
+	test.Nil(err)
+	keys := &sp1.VerifierKeys{Groth16: vk, Groth16Hash: common.Hash{1, 2, 3, 4}}
+	prover := &Sp1Prover{}
+	prover.SetVerifierKeys(keys)
+
+	output := []byte("output")
+	seal, err := td.Prove([]*big.Int{SP1_PROGRAM_VKHASH.Big(), sp1.HashPublicValues(output)})
+	test.Nil(err)
+	proof := &ZkProof{
+		Type:   ZkTypeSuccinct,
+		Output: output,
+		Proof:  append(keys.Groth16Hash[:4:4], seal...),
+	}
+	test.Nil(prover.Verify(proof))
+
+	proof.Output = []byte("other")
+	test.True(logex.Equal(prover.Verify(proof), groth16.ErrVerification))
+	proof.Proof[0] ^= 1
+	test.True(logex.Equal(prover.Verify(proof), sp1.ErrSelectorMismatch))
+
+	plonkVk, plonkTd, err := testutil.NewPlonkTestKey(2, 1)
+	test.Nil(err)
+	keys.Plonk, keys.PlonkHash = plonkVk, common.Hash{5, 6, 7, 8}
+	seal, err = plonkTd.Prove([]*big.Int{SP1_PROGRAM_VKHASH.Big(), sp1.HashPublicValues(output)})
+	test.Nil(err)
+	client, err := NewZkProofClient(&ZkProofConfig{
+		Provers:      []Prover{prover},
+		ProofModes:   map[ZkType]ProofMode{ZkTypeSuccinct: ProofModePlonk},
+		VerifyProofs: true,
+	}, nil)
+	test.Nil(err)
+	proof = &ZkProof{
+		Type:   ZkTypeSuccinct,
+		Mode:   ProofModePlonk,
+		Output: output,
+		Proof:  append(keys.PlonkHash[:4:4], seal...),
+	}
+	test.Nil(client.VerifyProof(proof))
+	proof.Output = []byte("other")
+	test.True(logex.Equal(client.VerifyProof(proof), plonk.ErrVerification))
+
+	// the compressed proofs are verified by the recursion
+	proof.Mode = ProofModeSuccinct
+	test.True(logex.Equal(client.VerifyProof(proof), ErrUnverifiableMode))
+}
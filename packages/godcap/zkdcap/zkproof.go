@@ -2,12 +2,19 @@ package zkdcap
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
 
 	"github.com/automata-network/dcap-sdk/packages/godcap/bonsai"
+	"github.com/automata-network/dcap-sdk/packages/godcap/observe"
+	"github.com/automata-network/dcap-sdk/packages/godcap/parser"
 	"github.com/automata-network/dcap-sdk/packages/godcap/pccs"
 	"github.com/automata-network/dcap-sdk/packages/godcap/sp1"
 	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // ZkType represents the type of zero-knowledge proof
@@ -20,24 +27,109 @@ var (
 	ZkTypeSuccinct = ZkType(2)
 )
 
-// ZkProof holds the proof and output data for a zero-knowledge proof
+// ZkProof holds the proof and output data for a zero-knowledge proof.
+// See encoding.go for the JSON and binary encodings.
 type ZkProof struct {
-	Type   ZkType
+	Type ZkType
+	// Mode is the kind of the proof, only the OnChain modes are accepted by the portal
+	Mode   ProofMode
 	Output []byte
 	Proof  []byte
+
+	// ProgramID of the prover, e.g. the image id or the vkhash
+	ProgramID string
+	// InputDigest is the SHA-256 of the guest input
+	InputDigest common.Hash
+	// CollateralBlock is the block at which the collateral was fetched, 0 if unknown
+	CollateralBlock uint64
+}
+
+// VerifiedOutput decodes the quote verification output committed by the proof
+func (p *ZkProof) VerifiedOutput() (*parser.VerifiedOutput, error) {
+	return decodeVerifiedOutput(p.Output)
+}
+
+// decodeVerifiedOutput decodes the quote verification output of the guest output
+func decodeVerifiedOutput(output []byte) (*parser.VerifiedOutput, error) {
+	if len(output) < 2 {
+		return nil, logex.NewErrorf("zk output too short: %v", len(output))
+	}
+	size := int(binary.BigEndian.Uint16(output[:2]))
+	if len(output) < 2+size {
+		return nil, logex.NewErrorf("zk output too short: %v < %v", len(output), 2+size)
+	}
+	return parser.ParseVerifiedOutput(output[2 : 2+size])
 }
 
 // ZkProofConfig holds the configuration for the ZkProofClient
 type ZkProofConfig struct {
 	Bonsai *bonsai.Config `json:"bonsai"`
 	Sp1    *sp1.Config    `json:"sp1"`
+
+	// ProgramVersion selects the guest, defaults to DEFAULT_PROGRAM_VERSION
+	ProgramVersion string `json:"program_version"`
+	// Programs take precedence over the registered programs, e.g. the guests built after the release
+	Programs []*Program `json:"programs"`
+
+	// Provers overrides the registered provers, e.g. a self-hosted prover or a mock
+	Provers []Prover `json:"-"`
+	// JobStore persists the submitted proof jobs, defaults to a MemoryJobStore
+	JobStore JobStore `json:"-"`
+	// ProofCache is used by ProveQuote if not nil
+	ProofCache ProofCache `json:"-"`
+	// CacheBucketSecs is the period in which a cached proof is reused, defaults to 3600
+	CacheBucketSecs int `json:"cache_bucket_secs"`
+	// ProofModes selects the proof mode of the ZkTypes, defaults to ProofModeGroth16
+	ProofModes map[ZkType]ProofMode `json:"proof_modes"`
+	// VerifyProofs verifies the proofs off-chain once they are generated, see VerifyingProver
+	VerifyProofs bool `json:"verify_proofs"`
+	// MaxProofAgeSecs rejects the proofs verified earlier before they're submitted, 0 disables it, see CheckFreshness
+	MaxProofAgeSecs int `json:"max_proof_age_secs"`
+	// CheckCollateralHashes rejects the proofs whose collateral was updated on chain before they're submitted
+	CheckCollateralHashes bool `json:"check_collateral_hashes"`
+	// Now is the clock of the timestamp in the guest input, defaults to time.Now
+	Now func() time.Time `json:"-"`
+	// Observer receives the progress of the proofs, it's passed to the clients of Bonsai and SP1
+	Observer observe.Observer `json:"-"`
+}
+
+// Program returns the guest of ProgramVersion, from Programs or the registered programs
+func (cfg *ZkProofConfig) Program() (*Program, error) {
+	version := cfg.ProgramVersion
+	if version == "" {
+		version = DEFAULT_PROGRAM_VERSION
+	}
+	for _, program := range cfg.Programs {
+		if program.Version == version {
+			if err := program.validate(); err != nil {
+				return nil, logex.Trace(err)
+			}
+			return program, nil
+		}
+	}
+	return LookupProgram(version)
 }
 
 // ZkProofClient is a client for generating zero-knowledge proofs
 type ZkProofClient struct {
-	Bonsai *bonsai.Client
-	Sp1    *sp1.Client
-	ps     *pccs.Client
+	Bonsai  *bonsai.Client
+	Sp1     *sp1.Client
+	provers map[ZkType]Prover
+	jobs    JobStore
+	cache   ProofCache
+	bucket  time.Duration
+	now     func() time.Time
+	verify  bool
+	modes   map[ZkType]ProofMode
+	maxAge  time.Duration
+	ps      *pccs.Client
+	events  *observe.Emitter
+
+	// checkCollateral compares the collateral hashes of the proofs with the chain
+	checkCollateral bool
+
+	limitersMu sync.Mutex
+	limiters   map[ZkType]*rateLimiter
 }
 
 // NewZkProofClient creates a new ZkProofClient with the given configuration and server
@@ -45,85 +137,251 @@ func NewZkProofClient(cfg *ZkProofConfig, ps *pccs.Client) (*ZkProofClient, erro
 	if cfg == nil {
 		cfg = new(ZkProofConfig)
 	}
-	if cfg.Bonsai == nil {
-		cfg.Bonsai = new(bonsai.Config)
-	}
-	if cfg.Sp1 == nil {
-		cfg.Sp1 = new(sp1.Config)
-	}
-	if err := cfg.Bonsai.Init(); err != nil {
-		return nil, logex.Trace(err)
-	}
-	if err := cfg.Sp1.Init(); err != nil {
+	// the defaults are applied to a copy, the config may be shared by other clients
+	copied := *cfg
+	cfg = &copied
+	provers, err := newProvers(cfg)
+	if err != nil {
 		return nil, logex.Trace(err)
 	}
 
-	client := &ZkProofClient{ps: ps}
+	jobs := cfg.JobStore
+	if jobs == nil {
+		jobs = NewMemoryJobStore()
+	}
 
-	if cfg.Bonsai.ApiKey != "" {
-		bonsaiClient, err := bonsai.NewClient(cfg.Bonsai)
-		if err != nil {
-			return nil, logex.Trace(err)
+	if cfg.VerifyProofs {
+		// fail before paying for proofs which would be rejected by VerifyProof
+		for ty, mode := range cfg.ProofModes {
+			if verifier, ok := provers[ty].(ModeVerifier); ok && mode.OnChain() && !verifier.VerifiesMode(mode) {
+				return nil, ErrUnverifiableMode.Format(mode, ty)
+			}
 		}
-		client.Bonsai = bonsaiClient
 	}
 
-	if cfg.Sp1.PrivateKey != "" {
-		sp1Client, err := sp1.NewClient(cfg.Sp1)
-		if err != nil {
-			return nil, logex.Trace(err)
+	if cfg.CacheBucketSecs == 0 {
+		cfg.CacheBucketSecs = 3600
+	}
+	if cfg.ProofCache != nil {
+		// the proofs of the previous programs are no longer accepted
+		for ty, prover := range provers {
+			if err := cfg.ProofCache.Retain(ty, prover.ProgramID()); err != nil {
+				return nil, logex.Trace(err)
+			}
 		}
-		client.Sp1 = sp1Client
 	}
 
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	client := &ZkProofClient{
+		ps:      ps,
+		provers: provers,
+		jobs:    jobs,
+		cache:   cfg.ProofCache,
+		bucket:  time.Duration(cfg.CacheBucketSecs) * time.Second,
+		now:     now,
+		verify:  cfg.VerifyProofs,
+		modes:   cfg.ProofModes,
+		maxAge:  time.Duration(cfg.MaxProofAgeSecs) * time.Second,
+
+		checkCollateral: cfg.CheckCollateralHashes,
+	}
+	if cfg.Observer != nil {
+		client.events = observe.NewEmitter(observe.SourceZkDcap, cfg.Observer)
+	}
+	if prover, ok := provers[ZkTypeRiscZero].(*BonsaiProver); ok {
+		client.Bonsai = prover.Client
+	}
+	if prover, ok := provers[ZkTypeSuccinct].(*Sp1Prover); ok {
+		client.Sp1 = prover.Client
+	}
 	return client, nil
 }
 
+// Prover returns the prover of the ZkType
+func (c *ZkProofClient) Prover(ty ZkType) (Prover, error) {
+	prover, ok := c.provers[ty]
+	if !ok {
+		return nil, logex.NewErrorf("no prover for zkType: %v", ty)
+	}
+	return prover, nil
+}
+
+// ProofMode returns the mode of the proofs generated for the ZkType
+func (c *ZkProofClient) ProofMode(ty ZkType) ProofMode {
+	return c.modes[ty]
+}
+
+// modeProver returns the prover of the ZkType which supports the configured mode
+func (c *ZkProofClient) modeProver(ty ZkType) (Prover, ProofMode, error) {
+	prover, err := c.Prover(ty)
+	if err != nil {
+		return nil, 0, logex.Trace(err)
+	}
+	mode := c.ProofMode(ty)
+	if !SupportsMode(prover, mode) {
+		return nil, 0, ErrUnsupportedProofMode.Format(mode, ty)
+	}
+	return prover, mode, nil
+}
+
 // ProveQuote generates a zero-knowledge proof for the given quote and collateral
 func (c *ZkProofClient) ProveQuote(ctx context.Context, ty ZkType, quote []byte, collateral *Collateral) (*ZkProof, error) {
-	proof := &ZkProof{Type: ty}
-	switch ty {
-	case ZkTypeRiscZero:
-		if c.Bonsai == nil {
-			return nil, logex.NewError("BONSAI_API_KEY is required")
-		}
-		// Upload image to Bonsai if not already uploaded
-		err := c.Bonsai.UploadImage(BONSAI_IMAGE_ID, BONSAI_DCAP_GUEST_ELF)
-		if err != nil {
-			return nil, logex.Trace(err)
-		}
-		// Generate input for Bonsai proof
-		input := BonsaiGenerateInput(quote, collateral)
-		// Generate Bonsai proof
-		proveInfo, err := c.Bonsai.Prove(ctx, BONSAI_IMAGE_ID, input, bonsai.ReceiptGroth16)
+	return c.ProveQuoteAt(ctx, ty, quote, collateral, c.now())
+}
+
+// ProveQuoteAt generates a zero-knowledge proof of the quote verified at the timestamp
+func (c *ZkProofClient) ProveQuoteAt(ctx context.Context, ty ZkType, quote []byte, collateral *Collateral, timestamp time.Time) (*ZkProof, error) {
+	prover, mode, err := c.modeProver(ty)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	var cacheKey *ProofCacheKey
+	if c.cache != nil {
+		cacheKey = NewProofCacheKey(prover, mode, quote, collateral, timestamp, c.bucket)
+		proof, err := c.cache.Get(cacheKey)
 		if err != nil {
-			return nil, logex.Trace(err)
-		}
-		// Set proof output and proof data
-		proof.Output = []byte(proveInfo.Receipt.Journal.Bytes)
-		groth16 := proveInfo.Receipt.Inner.Groth16
-		var selector [4]byte
-		binary.LittleEndian.PutUint32(selector[:], groth16.VerifierParameters[0])
-		proof.Proof = bonsai.Groth16Encode(selector, []byte(groth16.Seal))
-	case ZkTypeSuccinct:
-		if c.Sp1 == nil {
-			return nil, logex.NewError("NETWORK_PRIVATE_KEY is required")
+			logex.Error("proof cache:", err)
+		} else if proof != nil {
+			return proof, nil
 		}
+	}
 
-		// Generate input for SP1 proof
-		stdin := sp1.NewSP1StdinFromInput(Sp1GenerateInput(quote, collateral))
-		// Generate SP1 proof
-		res, err := c.Sp1.Prove(ctx, SP1_PROGRAM_VKHASH, stdin)
-		if err != nil {
-			return nil, logex.Trace(err)
+	input, err := prover.PrepareInput(quote, collateral, &InputOptions{Timestamp: timestamp})
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	inputDigest := sha256.Sum256(input)
+	proof, err := prover.Prove(ctx, input, mode)
+	if err == nil {
+		proof.ProgramID = prover.ProgramID()
+		proof.InputDigest = inputDigest
+		if collateral != nil {
+			proof.CollateralBlock = collateral.BlockNumber
 		}
-		// Set proof output and proof data
-		proofBytes, err := res.Bytes()
-		if err != nil {
-			return nil, logex.Trace(err)
+		// the succinct proofs are verified by the recursion which consumes them
+		if mode != ProofModeSuccinct {
+			err = c.VerifyProof(proof)
+		}
+	}
+	c.emitResult(ty, hex.EncodeToString(inputDigest[:]), err)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if cacheKey != nil {
+		if err := c.cache.Put(cacheKey, proof); err != nil {
+			logex.Error("proof cache:", err)
 		}
-		proof.Output = []byte(res.PublicValues.Buffer.Data)
-		proof.Proof = proofBytes
 	}
 	return proof, nil
 }
+
+// VerifyProof verifies the proof off-chain if ZkProofConfig.VerifyProofs is set
+func (c *ZkProofClient) VerifyProof(proof *ZkProof) error {
+	if !c.verify {
+		return nil
+	}
+	prover, err := c.Prover(proof.Type)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	verifier, ok := prover.(VerifyingProver)
+	if !ok {
+		return logex.NewErrorf("off-chain verification is not supported for zkType: %v", proof.Type)
+	}
+	if !VerifiesMode(prover, proof.Mode) {
+		return ErrUnverifiableMode.Format(proof.Mode, proof.Type)
+	}
+	if err := verifier.Verify(proof); err != nil {
+		return logex.Trace(err)
+	}
+	return nil
+}
+
+// Jobs returns the store of the submitted proof jobs
+func (c *ZkProofClient) Jobs() JobStore {
+	return c.jobs
+}
+
+// SubmitProof starts proving the quote without waiting for the proof.
+// The job is saved into the JobStore, and can be resumed by ResumeProof after a restart.
+func (c *ZkProofClient) SubmitProof(ctx context.Context, ty ZkType, quote []byte, collateral *Collateral) (*ProofJob, error) {
+	prover, mode, err := c.asyncProver(ty)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	input, err := prover.PrepareInput(quote, collateral, &InputOptions{Timestamp: c.now()})
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	job, err := prover.Submit(ctx, input, mode)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	job.CreatedAt = c.now()
+	if collateral != nil {
+		job.CollateralBlock = collateral.BlockNumber
+	}
+	if err := c.jobs.Save(job); err != nil {
+		return nil, logex.Trace(err)
+	}
+	c.events.Emit(&observe.Event{Kind: observe.EventSessionCreated, ID: job.ID, ZkType: uint8(ty)})
+	return job, nil
+}
+
+// ResumeProof waits for the proof of a submitted job.
+// The job is removed from the JobStore once the proof is ready.
+func (c *ZkProofClient) ResumeProof(ctx context.Context, job *ProofJob) (*ZkProof, error) {
+	prover, err := c.Prover(job.Type)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	asyncProver, ok := prover.(AsyncProver)
+	if !ok {
+		return nil, logex.NewErrorf("prover of zkType %v doesn't support async proving", job.Type)
+	}
+	// the job is resumed in the mode it was submitted
+	if !SupportsMode(prover, job.Mode) {
+		return nil, ErrUnsupportedProofMode.Format(job.Mode, job.Type)
+	}
+	proof, err := asyncProver.Resume(ctx, job, c.jobs.Save)
+	if err == nil {
+		proof.ProgramID = prover.ProgramID()
+		proof.InputDigest = job.InputDigest
+		proof.CollateralBlock = job.CollateralBlock
+		err = c.VerifyProof(proof)
+	}
+	c.emitResult(job.Type, job.ID, err)
+	if err != nil {
+		return nil, logex.Trace(err)
+	}
+	if err := c.jobs.Delete(job.ID); err != nil {
+		return nil, logex.Trace(err)
+	}
+	return proof, nil
+}
+
+// emitResult reports whether the proof of the ZkType is generated and verified
+func (c *ZkProofClient) emitResult(ty ZkType, id string, err error) {
+	ev := &observe.Event{Kind: observe.EventFulfilled, ID: id, ZkType: uint8(ty)}
+	if err != nil {
+		ev.Kind = observe.EventFailed
+		ev.Err = err
+	}
+	c.events.Emit(ev)
+}
+
+func (c *ZkProofClient) asyncProver(ty ZkType) (AsyncProver, ProofMode, error) {
+	prover, mode, err := c.modeProver(ty)
+	if err != nil {
+		return nil, 0, logex.Trace(err)
+	}
+	asyncProver, ok := prover.(AsyncProver)
+	if !ok {
+		return nil, 0, logex.NewErrorf("prover of zkType %v doesn't support async proving", ty)
+	}
+	return asyncProver, mode, nil
+}